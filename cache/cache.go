@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package cache provides an HTTP response cache for Sourcehut API clients.
+//
+// The cache is added to a client as middleware using sourcehut.Intercept, so
+// that responses served from the cache are not subject to rate limiting:
+//
+//	srhtClient := sourcehut.NewClient(
+//		sourcehut.Token(token),
+//		sourcehut.UserAgent(userAgent),
+//		sourcehut.Intercept(cache.Middleware(cache.NewMemoryStore(), time.Minute)),
+//	)
+//
+// Successful responses to GET requests are stored, keyed by URL and by the
+// identity of the access token that was used, so that clients with different
+// tokens never see each other's responses.
+// Responses are served from the cache without contacting the server until they
+// are older than the TTL, after which they are revalidated using the ETag or
+// Last-Modified header if the server provided one.
+//
+// A successful POST, PUT, PATCH, or DELETE request invalidates every cached
+// response for the same resource and for its parent collection, for all tokens.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+
+	// Stored is the time at which the response was last received or
+	// revalidated.
+	Stored time.Time `json:"stored"`
+}
+
+// Store is the storage used by the cache.
+// Implementations must be safe for concurrent use.
+//
+// Because the cache is an optimization, stores are expected to handle errors
+// (for example, by treating an entry that cannot be read as missing) instead
+// of reporting them.
+type Store interface {
+	// Get returns the entry stored under key, if any.
+	Get(key string) (Entry, bool)
+
+	// Set stores an entry under key, replacing any existing entry.
+	Set(key string, e Entry)
+
+	// DeletePrefix removes all entries whose key starts with prefix.
+	DeletePrefix(prefix string)
+}
+
+// Middleware returns sourcehut.Middleware that caches responses in store.
+// Cached responses are used without revalidation for ttl after they were
+// stored; if ttl is zero every use of a cached response is revalidated.
+//
+// The middleware should be added with sourcehut.Intercept: added with
+// sourcehut.Use it runs after rate limiting and for every retry, so responses
+// served from the cache still wait for the rate limiter.
+func Middleware(store Store, ttl time.Duration) sourcehut.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &transport{next: next, store: store, ttl: ttl, now: time.Now}
+	}
+}
+
+type transport struct {
+	next  http.RoundTripper
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet:
+		return t.get(req)
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && resp.StatusCode < 400 {
+			t.invalidate(req)
+		}
+		return resp, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+func (t *transport) get(req *http.Request) (*http.Response, error) {
+	key := cacheKey(req)
+	e, ok := t.store.Get(key)
+	if ok && t.now().Sub(e.Stored) < t.ttl {
+		return e.response(req), nil
+	}
+
+	out := req
+	if ok {
+		etag, modified := e.Header.Get("ETag"), e.Header.Get("Last-Modified")
+		if etag != "" || modified != "" {
+			out = req.Clone(req.Context())
+			if etag != "" {
+				out.Header.Set("If-None-Match", etag)
+			}
+			if modified != "" {
+				out.Header.Set("If-Modified-Since", modified)
+			}
+		}
+	}
+
+	resp, err := t.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	if ok && out != req && resp.StatusCode == http.StatusNotModified {
+		/* #nosec */
+		resp.Body.Close()
+		e.Header = e.Header.Clone()
+		for k, v := range resp.Header {
+			if k != "Content-Length" {
+				e.Header[k] = v
+			}
+		}
+		e.Stored = t.now()
+		t.store.Set(key, e)
+		return e.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK || noStore(resp.Header) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	/* #nosec */
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.store.Set(key, Entry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Stored:     t.now(),
+	})
+	return resp, nil
+}
+
+// invalidate removes cached responses for the resource that req modified and
+// for its parent collection.
+func (t *transport) invalidate(req *http.Request) {
+	u := *req.URL
+	u.RawQuery, u.Fragment = "", ""
+	p := strings.TrimSuffix(u.Path, "/")
+	u.Path, u.RawPath = p, strings.TrimSuffix(u.RawPath, "/")
+	// The resource itself, its queries, and its children, but not siblings
+	// that share a prefix with it.
+	self := u.String()
+	t.store.DeletePrefix(self + " ")
+	t.store.DeletePrefix(self + "?")
+	t.store.DeletePrefix(self + "/")
+	if i := strings.LastIndexByte(p, '/'); i > 0 {
+		u.Path, u.RawPath = p[:i], ""
+		parent := u.String()
+		// Only the parent itself and its queries, the rest of its children are
+		// not affected by the write.
+		t.store.DeletePrefix(parent + "?")
+		t.store.DeletePrefix(parent + " ")
+	}
+}
+
+// cacheKey returns the key for req, which starts with the URL so that all
+// entries for a resource can be removed by prefix.
+func cacheKey(req *http.Request) string {
+	u := *req.URL
+	u.Fragment = ""
+	return u.String() + " " + tokenID(req.Header.Get("Authorization"))
+}
+
+// tokenID returns an identifier for the credentials in an Authorization header
+// that does not reveal them.
+func tokenID(authorization string) string {
+	if authorization == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(authorization))
+	return hex.EncodeToString(sum[:16])
+}
+
+func noStore(h http.Header) bool {
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(d), "no-store") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e Entry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,305 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/cache"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+)
+
+// resourceServer serves versioned JSON documents and supports conditional
+// requests using ETag and Last-Modified.
+type resourceServer struct {
+	mu          sync.Mutex
+	version     map[string]int
+	full        int
+	notModified int
+	etag        bool
+}
+
+func (s *resourceServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Method != "GET" {
+		s.version[req.URL.Path]++
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	v := s.version[req.URL.Path]
+	modified := time.Date(2020, 1, 1, 0, v, 0, 0, time.UTC).Format(http.TimeFormat)
+	etag := fmt.Sprintf(`"%d"`, v)
+	if s.etag {
+		w.Header().Set("ETag", etag)
+		if req.Header.Get("If-None-Match") == etag {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else {
+		w.Header().Set("Last-Modified", modified)
+		if req.Header.Get("If-Modified-Since") == modified {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	s.full++
+	fmt.Fprintf(w, `{"path": %q, "version": %d, "auth": %q}`, req.URL.Path, v, req.Header.Get("Authorization"))
+}
+
+func (s *resourceServer) counts() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.full, s.notModified
+}
+
+type doc struct {
+	Path    string `json:"path"`
+	Version int    `json:"version"`
+	Auth    string `json:"auth"`
+}
+
+func newServer(t *testing.T, etag bool) (*resourceServer, *httptest.Server) {
+	t.Helper()
+	rs := &resourceServer{version: make(map[string]int), etag: etag}
+	server := httptest.NewUnstartedServer(rs)
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+	return rs, server
+}
+
+func newClient(server *httptest.Server, token string, store cache.Store, ttl time.Duration) sourcehut.Client {
+	return sourcehut.NewClient(
+		sourcehut.Token(token),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+		sourcehut.Intercept(cache.Middleware(store, ttl)),
+	)
+}
+
+func do(t *testing.T, c sourcehut.Client, method, u string) doc {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), method, u, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d doc
+	var v interface{} = &d
+	if method != "GET" {
+		v = nil
+	}
+	_, err = c.Do(req, v)
+	if err != nil {
+		t.Fatalf("Error on %s %s: %v", method, u, err)
+	}
+	return d
+}
+
+func TestRevalidate(t *testing.T) {
+	for _, etag := range []bool{true, false} {
+		t.Run(fmt.Sprintf("etag=%t", etag), func(t *testing.T) {
+			rs, server := newServer(t, etag)
+			c := newClient(server, "token", cache.NewMemoryStore(), 0)
+
+			for i := 0; i < 3; i++ {
+				d := do(t, c, "GET", server.URL+"/api/repos")
+				if d.Path != "/api/repos" || d.Version != 0 {
+					t.Fatalf("Unexpected response %d: %+v", i, d)
+				}
+			}
+			if full, notModified := rs.counts(); full != 1 || notModified != 2 {
+				t.Errorf("Expected 1 full response and 2 revalidations, got %d and %d", full, notModified)
+			}
+		})
+	}
+}
+
+func TestTTL(t *testing.T) {
+	rs, server := newServer(t, true)
+	c := newClient(server, "token", cache.NewMemoryStore(), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		do(t, c, "GET", server.URL+"/api/repos")
+	}
+	if full, notModified := rs.counts(); full != 1 || notModified != 0 {
+		t.Errorf("Expected a single request while fresh, got %d full and %d revalidations", full, notModified)
+	}
+}
+
+func TestTokenIdentity(t *testing.T) {
+	rs, server := newServer(t, true)
+	store := cache.NewMemoryStore()
+	a := newClient(server, "a", store, time.Hour)
+	b := newClient(server, "b", store, time.Hour)
+
+	if d := do(t, a, "GET", server.URL+"/api/user"); d.Auth != "token a" {
+		t.Errorf("Unexpected response for a: %+v", d)
+	}
+	if d := do(t, b, "GET", server.URL+"/api/user"); d.Auth != "token b" {
+		t.Errorf("Response for a was served to b: %+v", d)
+	}
+	if full, _ := rs.counts(); full != 2 {
+		t.Errorf("Expected one request per token, got %d", full)
+	}
+}
+
+func TestInvalidateOnWrite(t *testing.T) {
+	rs, server := newServer(t, true)
+	store := cache.NewMemoryStore()
+	c := newClient(server, "token", store, time.Hour)
+	other := newClient(server, "other", store, time.Hour)
+
+	do(t, c, "GET", server.URL+"/api/repos")
+	do(t, c, "GET", server.URL+"/api/repos?start=2")
+	do(t, other, "GET", server.URL+"/api/repos/a")
+	do(t, c, "GET", server.URL+"/api/repos/b")
+	do(t, c, "GET", server.URL+"/api/repos/a/refs")
+	do(t, c, "GET", server.URL+"/api/repos/ab")
+
+	// A write to a repo invalidates it, its children, and the list of repos
+	// for every token, but not other repos, even if their names start with the
+	// same characters.
+	do(t, c, "PUT", server.URL+"/api/repos/a")
+	for _, tc := range []struct {
+		c       sourcehut.Client
+		path    string
+		fetched bool
+	}{
+		{c: c, path: "/api/repos", fetched: true},
+		{c: c, path: "/api/repos?start=2", fetched: true},
+		{c: other, path: "/api/repos/a", fetched: true},
+		{c: c, path: "/api/repos/b"},
+		{c: c, path: "/api/repos/a/refs", fetched: true},
+		{c: c, path: "/api/repos/ab"},
+	} {
+		before, _ := rs.counts()
+		do(t, tc.c, "GET", server.URL+tc.path)
+		after, _ := rs.counts()
+		if fetched := after > before; fetched != tc.fetched {
+			t.Errorf("Unexpected cache use for %s: want fetched=%t, got %t", tc.path, tc.fetched, fetched)
+		}
+	}
+}
+
+func TestDiskStore(t *testing.T) {
+	rs, server := newServer(t, true)
+	dir := t.TempDir()
+
+	store, err := cache.NewDiskStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	do(t, newClient(server, "token", store, time.Hour), "GET", server.URL+"/api/repos")
+	do(t, newClient(server, "token", store, time.Hour), "GET", server.URL+"/api/repos/a")
+
+	// A new store in the same directory sees the entries.
+	store, err = cache.NewDiskStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newClient(server, "token", store, time.Hour)
+	d := do(t, c, "GET", server.URL+"/api/repos")
+	if d.Path != "/api/repos" {
+		t.Errorf("Unexpected cached response: %+v", d)
+	}
+	if full, _ := rs.counts(); full != 2 {
+		t.Errorf("Expected cached response to be used, got %d requests", full)
+	}
+
+	do(t, c, "DELETE", server.URL+"/api/repos/a")
+	d = do(t, c, "GET", server.URL+"/api/repos/a")
+	if d.Version != 1 {
+		t.Errorf("Expected deleted resource to be refetched, got %+v", d)
+	}
+	if full, _ := rs.counts(); full != 3 {
+		t.Errorf("Expected invalidated resource to be refetched, got %d requests", full)
+	}
+}
+
+func TestRateLimitedHits(t *testing.T) {
+	rs, server := newServer(t, true)
+	c := sourcehut.NewClient(
+		sourcehut.Token("token"),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+		sourcehut.RateLimit(sourcehut.RateLimitPolicy{Rate: 0.001, Burst: 1, FailFast: true}),
+		sourcehut.Intercept(cache.Middleware(cache.NewMemoryStore(), time.Hour)),
+	)
+
+	// Only the first request reaches the limiter, the others are served from
+	// the cache and would fail if they needed a token.
+	for i := 0; i < 3; i++ {
+		do(t, c, "GET", server.URL+"/api/repos")
+	}
+	if full, _ := rs.counts(); full != 1 {
+		t.Errorf("Expected a single request, got %d", full)
+	}
+}
+
+func TestRetriedOnce(t *testing.T) {
+	var mu sync.Mutex
+	var served, failed int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		served++
+		if served == 1 {
+			failed++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"path": "/api/repos"}`)
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+
+	var seen int
+	count := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			seen++
+			return next.RoundTrip(req)
+		})
+	}
+	c := sourcehut.NewClient(
+		sourcehut.Token("token"),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+		sourcehut.Retry(sourcehut.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+		sourcehut.Intercept(cache.Middleware(cache.NewMemoryStore(), time.Hour), count),
+	)
+
+	// The cache sees the response of the retry and not the failed attempt, and
+	// stores it for the next request.
+	for i := 0; i < 2; i++ {
+		if d := do(t, c, "GET", server.URL+"/api/repos"); d.Path != "/api/repos" {
+			t.Fatalf("Unexpected response %d: %+v", i, d)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if served != 2 || failed != 1 {
+		t.Errorf("Expected one failed attempt and one retry, got %d requests", served)
+	}
+	if seen != 1 {
+		t.Errorf("Expected the cache to send one request, got %d", seen)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps entries in memory.
+// The zero value is not usable, use NewMemoryStore instead.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+// Get satisfies the Store interface.
+func (s *MemoryStore) Get(key string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	return e, ok
+}
+
+// Set satisfies the Store interface.
+func (s *MemoryStore) Set(key string, e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = e
+}
+
+// DeletePrefix satisfies the Store interface.
+func (s *MemoryStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+// DiskStore is a Store that keeps each entry in a file in a directory, so that
+// cached responses survive restarts of the program.
+// Since responses may contain private data, files are only readable by the
+// current user.
+type DiskStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// diskEntry is the format of the files written by DiskStore.
+type diskEntry struct {
+	Key   string `json:"key"`
+	Entry Entry  `json:"entry"`
+}
+
+// NewDiskStore returns a DiskStore that keeps entries in dir, creating it if
+// it does not exist.
+func NewDiskStore(dir string) (*DiskStore, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+func (s *DiskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get satisfies the Store interface.
+func (s *DiskStore) Get(key string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	de, ok := s.read(s.path(key))
+	if !ok || de.Key != key {
+		return Entry{}, false
+	}
+	return de.Entry, true
+}
+
+func (s *DiskStore) read(name string) (diskEntry, bool) {
+	var de diskEntry
+	/* #nosec */
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return de, false
+	}
+	err = json.Unmarshal(b, &de)
+	return de, err == nil
+}
+
+// Set satisfies the Store interface.
+func (s *DiskStore) Set(key string, e Entry) {
+	b, err := json.Marshal(diskEntry{Key: key, Entry: e})
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(key))
+	}
+	if err != nil {
+		/* #nosec */
+		_ = os.Remove(f.Name())
+	}
+}
+
+// DeletePrefix satisfies the Store interface.
+func (s *DiskStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		if de, ok := s.read(name); !ok || strings.HasPrefix(de.Key, prefix) {
+			/* #nosec */
+			_ = os.Remove(name)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut
+
+import (
+	"context"
+)
+
+// CallInfo describes the API call that an HTTP request is part of.
+// The service clients attach it to the context of every request they make so
+// that middleware, such as metrics or tracing, can identify calls without
+// having to parse URLs.
+type CallInfo struct {
+	// Service is the name of the Sourcehut service, eg. "git" or "meta".
+	Service string
+
+	// Endpoint is the path of the endpoint relative to the service's API base
+	// URL with any parameters replaced by placeholders, eg. "repos/{name}".
+	Endpoint string
+
+	// Page is the 1-based number of the page for requests made by an Iter, or 0
+	// for other requests.
+	Page int
+}
+
+type callInfoKey struct{}
+
+// WithCallInfo returns a copy of ctx that carries info.
+func WithCallInfo(ctx context.Context, info CallInfo) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, info)
+}
+
+// CallInfoFromContext returns the CallInfo carried by ctx, if any.
+func CallInfoFromContext(ctx context.Context) (CallInfo, bool) {
+	info, ok := ctx.Value(callInfoKey{}).(CallInfo)
+	return info, ok
+}
+
+// withPage returns a copy of ctx whose CallInfo has the provided page number.
+func withPage(ctx context.Context, page int) context.Context {
+	info, _ := CallInfoFromContext(ctx)
+	info.Page = page
+	return WithCallInfo(ctx, info)
+}
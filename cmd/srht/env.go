@@ -8,11 +8,7 @@ import (
 	"fmt"
 	"os"
 
-	"git.sr.ht/~wombelix/sourcehut-go/git"
-	"git.sr.ht/~wombelix/sourcehut-go/lists"
-	"git.sr.ht/~wombelix/sourcehut-go/meta"
-	"git.sr.ht/~wombelix/sourcehut-go/paste"
-	"git.sr.ht/~wombelix/sourcehut-go/todo"
+	"git.sr.ht/~wombelix/sourcehut-go"
 )
 
 // BUG(ssw): Tool does not load a config file or source .env files if present.
@@ -25,13 +21,17 @@ func defEnv(key, def string) string {
 	return env
 }
 
+// envVars is the configuration loaded from the environment.
+// The base URL of each service is derived from the domain unless it is
+// overridden by the service's own variable.
 type envVars struct {
-	token string
-	paste string
-	meta  string
-	lists string
-	git   string
-	todo  string
+	token  string
+	domain string
+	paste  string
+	meta   string
+	lists  string
+	git    string
+	todo   string
 }
 
 func (env envVars) String() string {
@@ -43,21 +43,23 @@ func (env envVars) String() string {
 		redactedToken = env.token[:8] + redactedToken
 	}
 	return fmt.Sprintf(`SRHT_TOKEN      = %q
+SRHT_DOMAIN     = %q
 SRHT_META_BASE  = %q
 SRHT_PASTE_BASE = %q
 SRHT_LISTS_BASE = %q
 SRHT_GIT_BASE   = %q
 SRHT_TODO_BASE  = %q
-`, redactedToken, env.meta, env.paste, env.lists, env.git, env.todo)
+`, redactedToken, env.domain, env.meta, env.paste, env.lists, env.git, env.todo)
 }
 
 func newEnv() envVars {
 	return envVars{
-		token: os.Getenv("SRHT_TOKEN"),
-		paste: defEnv("SRHT_PASTE_BASE", paste.BaseURL),
-		meta:  defEnv("SRHT_META_BASE", meta.BaseURL),
-		lists: defEnv("SRHT_LISTS_BASE", lists.BaseURL),
-		git:   defEnv("SRHT_GIT_BASE", git.BaseURL),
-		todo:  defEnv("SRHT_TODO_BASE", todo.BaseURL),
+		token:  os.Getenv("SRHT_TOKEN"),
+		domain: defEnv("SRHT_DOMAIN", sourcehut.DefaultDomain),
+		paste:  os.Getenv("SRHT_PASTE_BASE"),
+		meta:   os.Getenv("SRHT_META_BASE"),
+		lists:  os.Getenv("SRHT_LISTS_BASE"),
+		git:    os.Getenv("SRHT_GIT_BASE"),
+		todo:   os.Getenv("SRHT_TODO_BASE"),
 	}
 }
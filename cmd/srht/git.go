@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"git.sr.ht/~wombelix/sourcehut-go"
@@ -12,10 +13,9 @@ import (
 	"mellium.im/cli"
 )
 
-func gitCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
+func gitCmd(ctx context.Context, inst *sourcehut.Instance) (*cli.Command, error) {
 	client, err := git.NewClient(
-		git.SrhtClient(srhtClient),
-		git.Base(env.git),
+		git.Instance(inst),
 	)
 	if err != nil {
 		return nil, err
@@ -25,8 +25,8 @@ func gitCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 		Usage:       "git <command> [options]",
 		Description: "Manipulate Git repos.",
 		Commands: []*cli.Command{
-			gitReposCmd(client),
-			gitVersionCmd(client),
+			gitReposCmd(ctx, client),
+			gitVersionCmd(ctx, client),
 		},
 		Run: func(c *cli.Command, _ ...string) error {
 			c.Help()
@@ -35,12 +35,12 @@ func gitCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 	}, nil
 }
 
-func gitVersionCmd(client *git.Client) *cli.Command {
+func gitVersionCmd(ctx context.Context, client *git.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "version",
 		Description: "Shows the version of the Git endpoint.",
 		Run: func(c *cli.Command, ids ...string) error {
-			ver, err := client.Version()
+			ver, err := client.Version(ctx)
 			if err != nil {
 				return err
 			}
@@ -50,7 +50,7 @@ func gitVersionCmd(client *git.Client) *cli.Command {
 	}
 }
 
-func gitReposCmd(client *git.Client) *cli.Command {
+func gitReposCmd(ctx context.Context, client *git.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "repos username",
 		Description: "List all of the users repos.",
@@ -64,7 +64,7 @@ func gitReposCmd(client *git.Client) *cli.Command {
 				return nil
 			}
 
-			repos, err := client.Repos(username)
+			repos, err := client.Repos(ctx, username)
 			if err != nil {
 				return err
 			}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -13,10 +14,9 @@ import (
 	"mellium.im/cli"
 )
 
-func pgpCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
+func pgpCmd(ctx context.Context, inst *sourcehut.Instance) (*cli.Command, error) {
 	client, err := meta.NewClient(
-		meta.SrhtClient(srhtClient),
-		meta.Base(env.meta),
+		meta.Instance(inst),
 	)
 	if err != nil {
 		return nil, err
@@ -26,10 +26,10 @@ func pgpCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 		Usage:       "pgp <command> [options]",
 		Description: "Account PGP key commands.",
 		Commands: []*cli.Command{
-			deletePGPKeyCmd(client),
-			getPGPKeyCmd(client),
-			listPGPKeyCmd(client),
-			newPGPKeyCmd(client),
+			deletePGPKeyCmd(ctx, client),
+			getPGPKeyCmd(ctx, client),
+			listPGPKeyCmd(ctx, client),
+			newPGPKeyCmd(ctx, client),
 		},
 		Run: func(c *cli.Command, _ ...string) error {
 			c.Help()
@@ -38,7 +38,7 @@ func pgpCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 	}, nil
 }
 
-func getPGPKeyCmd(client *meta.Client) *cli.Command {
+func getPGPKeyCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "get <id>",
 		Description: `Show the PGP key with the given ID.`,
@@ -52,7 +52,7 @@ func getPGPKeyCmd(client *meta.Client) *cli.Command {
 				return err
 			}
 
-			k, err := client.GetPGPKey(id)
+			k, err := client.GetPGPKey(ctx, id)
 			if err != nil {
 				return err
 			}
@@ -63,7 +63,7 @@ func getPGPKeyCmd(client *meta.Client) *cli.Command {
 	}
 }
 
-func deletePGPKeyCmd(client *meta.Client) *cli.Command {
+func deletePGPKeyCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "delete <id>",
 		Description: `Delete the PGP key with the given ID.`,
@@ -77,12 +77,12 @@ func deletePGPKeyCmd(client *meta.Client) *cli.Command {
 				return err
 			}
 
-			return client.DeletePGPKey(id)
+			return client.DeletePGPKey(ctx, id)
 		},
 	}
 }
 
-func newPGPKeyCmd(client *meta.Client) *cli.Command {
+func newPGPKeyCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "new <key (authorized_keys format)>",
 		Description: `Authorize a new PGP key.`,
@@ -92,7 +92,7 @@ func newPGPKeyCmd(client *meta.Client) *cli.Command {
 				return errWrongArgs
 			}
 
-			k, err := client.NewPGPKey(args[0])
+			k, err := client.NewPGPKey(ctx, args[0])
 			if err != nil {
 				return err
 			}
@@ -103,7 +103,7 @@ func newPGPKeyCmd(client *meta.Client) *cli.Command {
 	}
 }
 
-func listPGPKeyCmd(client *meta.Client) *cli.Command {
+func listPGPKeyCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "list",
 		Description: `List all authorized PGP keys.`,
@@ -113,7 +113,7 @@ func listPGPKeyCmd(client *meta.Client) *cli.Command {
 				return errWrongArgs
 			}
 
-			iter, err := client.ListPGPKeys()
+			iter, err := client.ListPGPKeys(ctx)
 			if err != nil {
 				return err
 			}
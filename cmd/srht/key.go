@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -17,10 +18,9 @@ var (
 	errWrongArgs = fmt.Errorf("Wrong number of arguments")
 )
 
-func keyCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
+func keyCmd(ctx context.Context, inst *sourcehut.Instance) (*cli.Command, error) {
 	client, err := meta.NewClient(
-		meta.SrhtClient(srhtClient),
-		meta.Base(env.meta),
+		meta.Instance(inst),
 	)
 	if err != nil {
 		return nil, err
@@ -30,10 +30,10 @@ func keyCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 		Usage:       "key <command> [options]",
 		Description: "Account SSH key commands.",
 		Commands: []*cli.Command{
-			deleteSSHKeyCmd(client),
-			getSSHKeyCmd(client),
-			listSSHKeyCmd(client),
-			newSSHKeyCmd(client),
+			deleteSSHKeyCmd(ctx, client),
+			getSSHKeyCmd(ctx, client),
+			listSSHKeyCmd(ctx, client),
+			newSSHKeyCmd(ctx, client),
 		},
 		Run: func(c *cli.Command, _ ...string) error {
 			c.Help()
@@ -42,7 +42,7 @@ func keyCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 	}, nil
 }
 
-func getSSHKeyCmd(client *meta.Client) *cli.Command {
+func getSSHKeyCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "get <id>",
 		Description: `Show the SSH key with the given ID.`,
@@ -56,7 +56,7 @@ func getSSHKeyCmd(client *meta.Client) *cli.Command {
 				return err
 			}
 
-			k, err := client.GetSSHKey(id)
+			k, err := client.GetSSHKey(ctx, id)
 			if err != nil {
 				return err
 			}
@@ -67,7 +67,7 @@ func getSSHKeyCmd(client *meta.Client) *cli.Command {
 	}
 }
 
-func deleteSSHKeyCmd(client *meta.Client) *cli.Command {
+func deleteSSHKeyCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "delete <id>",
 		Description: `Delete the SSH key with the given ID.`,
@@ -81,22 +81,37 @@ func deleteSSHKeyCmd(client *meta.Client) *cli.Command {
 				return err
 			}
 
-			return client.DeleteSSHKey(id)
+			return client.DeleteSSHKey(ctx, id)
 		},
 	}
 }
 
-func newSSHKeyCmd(client *meta.Client) *cli.Command {
+func newSSHKeyCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
-		Usage:       "new <key (authorized_keys format)>",
-		Description: `Authorize a new SSH key.`,
+		Usage: "new <key (authorized_keys format)>",
+		Description: `Authorize a new SSH key.
+
+The key is checked before it is uploaded and any options are removed.
+`,
 		Run: func(c *cli.Command, args ...string) error {
 			if len(args) != 1 {
 				c.Help()
 				return errWrongArgs
 			}
 
-			k, err := client.NewSSHKey(args[0])
+			local, err := meta.ParseAuthorizedKey(args[0])
+			if err != nil {
+				return err
+			}
+			existing, ok, err := client.FindSSHKey(ctx, local)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return fmt.Errorf("key %s is already authorized with ID %d", local.FingerprintSHA256(), existing.ID)
+			}
+
+			k, err := client.NewSSHKey(ctx, local.String())
 			if err != nil {
 				return err
 			}
@@ -107,7 +122,7 @@ func newSSHKeyCmd(client *meta.Client) *cli.Command {
 	}
 }
 
-func listSSHKeyCmd(client *meta.Client) *cli.Command {
+func listSSHKeyCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "list",
 		Description: `List all authorized SSH keys.`,
@@ -117,7 +132,7 @@ func listSSHKeyCmd(client *meta.Client) *cli.Command {
 				return errWrongArgs
 			}
 
-			iter, err := client.ListSSHKeys()
+			iter, err := client.ListSSHKeys(ctx)
 			if err != nil {
 				return err
 			}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -13,10 +14,9 @@ import (
 	"mellium.im/cli"
 )
 
-func listsCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
+func listsCmd(ctx context.Context, inst *sourcehut.Instance) (*cli.Command, error) {
 	client, err := lists.NewClient(
-		lists.SrhtClient(srhtClient),
-		lists.Base(env.lists),
+		lists.Instance(inst),
 	)
 	if err != nil {
 		return nil, err
@@ -26,10 +26,10 @@ func listsCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 		Usage:       "lists <command> [options]",
 		Description: "Manipulate mailing lists.",
 		Commands: []*cli.Command{
-			getListsUserCmd(client),
-			listEmailsCmd(client),
-			listPostsCmd(client),
-			listsVersionCmd(client),
+			getListsUserCmd(ctx, client),
+			listEmailsCmd(ctx, client),
+			listPostsCmd(ctx, client),
+			listsVersionCmd(ctx, client),
 		},
 		Run: func(c *cli.Command, _ ...string) error {
 			c.Help()
@@ -38,7 +38,7 @@ func listsCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 	}, nil
 }
 
-func getListsUserCmd(client *lists.Client) *cli.Command {
+func getListsUserCmd(ctx context.Context, client *lists.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "user [username]",
 		Description: `Show the named or authenticated user's profile.`,
@@ -52,7 +52,7 @@ func getListsUserCmd(client *lists.Client) *cli.Command {
 				c.Help()
 				return nil
 			}
-			user, err := client.GetUser(username)
+			user, err := client.GetUser(ctx, username)
 			if err != nil {
 				return err
 			}
@@ -63,12 +63,12 @@ func getListsUserCmd(client *lists.Client) *cli.Command {
 	}
 }
 
-func listsVersionCmd(client *lists.Client) *cli.Command {
+func listsVersionCmd(ctx context.Context, client *lists.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "version",
 		Description: "Shows the version of the lists endpoint.",
 		Run: func(c *cli.Command, ids ...string) error {
-			ver, err := client.Version()
+			ver, err := client.Version(ctx)
 			if err != nil {
 				return err
 			}
@@ -78,7 +78,7 @@ func listsVersionCmd(client *lists.Client) *cli.Command {
 	}
 }
 
-func listEmailsCmd(client *lists.Client) *cli.Command {
+func listEmailsCmd(ctx context.Context, client *lists.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "emails username",
 		Description: "List all emails sent by the given user.",
@@ -92,7 +92,7 @@ func listEmailsCmd(client *lists.Client) *cli.Command {
 				return nil
 			}
 
-			posts, err := client.ListEmails(username)
+			posts, err := client.ListEmails(ctx, username)
 			if err != nil {
 				return err
 			}
@@ -105,7 +105,7 @@ func listEmailsCmd(client *lists.Client) *cli.Command {
 	}
 }
 
-func listPostsCmd(client *lists.Client) *cli.Command {
+func listPostsCmd(ctx context.Context, client *lists.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "posts username/listname",
 		Description: "List all emails to the list owned by the given username.",
@@ -119,7 +119,7 @@ func listPostsCmd(client *lists.Client) *cli.Command {
 				return nil
 			}
 
-			posts, err := client.ListPosts(parts[0], parts[1])
+			posts, err := client.ListPosts(ctx, parts[0], parts[1])
 			if err != nil {
 				return err
 			}
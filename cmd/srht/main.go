@@ -5,8 +5,13 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
 
 	"git.sr.ht/~wombelix/sourcehut-go"
 	"mellium.im/cli"
@@ -22,44 +27,84 @@ const userAgent = "git.sr.ht/~wombelix/sourcehut-go/cmd/srht"
 
 func main() {
 	logger := log.New(os.Stderr, "", log.LstdFlags)
+
+	var (
+		verbose  bool
+		logLevel = defEnv("SRHT_LOGLEVEL", slog.LevelInfo.String())
+	)
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flags.BoolVar(&verbose, "v", false, "Log every API request, same as -log-level=debug")
+	flags.StringVar(&logLevel, "log-level", logLevel, "Log level: debug, info, warn, or error, overrides $SRHT_LOGLEVEL")
+	/* #nosec */
+	_ = flags.Parse(os.Args[1:])
+
+	level, ok := parseLogLevel(logLevel)
+	if !ok {
+		logger.Printf("Invalid log level %q, using %s", logLevel, level)
+	}
+	if verbose {
+		level = slog.LevelDebug
+	}
+
+	// Cancel any in-flight API requests if we're interrupted.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
 	env := newEnv()
-	srhtClient := sourcehut.NewClient(
+	clientOpts := []sourcehut.Option{
 		sourcehut.Token(env.token),
 		sourcehut.UserAgent(userAgent),
+		sourcehut.Logger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))),
+	}
+	// Without a token only public data can be read.
+	if env.token == "" {
+		clientOpts = append(clientOpts, sourcehut.Anonymous())
+	}
+	srhtClient := sourcehut.NewClient(clientOpts...)
+	inst, err := sourcehut.NewInstance(env.domain, srhtClient,
+		sourcehut.ServiceBase(sourcehut.ServiceMeta, env.meta),
+		sourcehut.ServiceBase(sourcehut.ServiceGit, env.git),
+		sourcehut.ServiceBase(sourcehut.ServiceTodo, env.todo),
+		sourcehut.ServiceBase(sourcehut.ServiceLists, env.lists),
+		sourcehut.ServiceBase(sourcehut.ServicePaste, env.paste),
 	)
+	if err != nil {
+		logger.Fatalf("Invalid instance configuration: %v", err)
+	}
 
-	user, err := userCmd(srhtClient, env)
+	user, err := userCmd(ctx, inst)
 	if err != nil {
 		logger.Fatal("Meta URL could not be parsed.")
 	}
-	key, err := keyCmd(srhtClient, env)
+	key, err := keyCmd(ctx, inst)
 	if err != nil {
 		logger.Fatal("Meta URL could not be parsed.")
 	}
-	pgp, err := pgpCmd(srhtClient, env)
+	pgp, err := pgpCmd(ctx, inst)
 	if err != nil {
 		logger.Fatal("Meta URL could not be parsed.")
 	}
-	paste, err := pasteCmd(srhtClient, env)
+	paste, err := pasteCmd(ctx, inst)
 	if err != nil {
 		logger.Fatal("Paste URL could not be parsed.")
 	}
-	lists, err := listsCmd(srhtClient, env)
+	lists, err := listsCmd(ctx, inst)
 	if err != nil {
 		logger.Fatal("Lists URL could not be parsed.")
 	}
-	git, err := gitCmd(srhtClient, env)
+	git, err := gitCmd(ctx, inst)
 	if err != nil {
 		logger.Fatal("Git URL could not be parsed.")
 	}
-	todo, err := todoCmd(srhtClient, env)
+	todo, err := todoCmd(ctx, inst)
 	if err != nil {
 		logger.Fatal("TODO URL could not be parsed.")
 	}
 
 	// Commands
 	cmds := &cli.Command{
-		Usage: os.Args[0],
+		Usage: os.Args[0] + " [options] <command>",
+		Flags: flags,
 	}
 	cmds.Commands = []*cli.Command{
 		aboutCmd(os.Stdout, version, commit, env),
@@ -68,12 +113,13 @@ func main() {
 		lists,
 		paste,
 		pgp,
+		servicesCmd(ctx, os.Stdout, inst),
 		user,
 		todo,
 		cli.Help(cmds),
 	}
 
-	err = cmds.Exec(os.Args[1:]...)
+	err = cmds.Exec(flags.Args()...)
 	switch err {
 	case cli.ErrInvalidCmd:
 		helpCmd := cli.Help(cmds)
@@ -91,3 +137,23 @@ func main() {
 		logger.Fatal(err)
 	}
 }
+
+// parseLogLevel parses the names of the slog levels (eg. "warn" or "info+2")
+// and the logrus level names that earlier versions accepted in $SRHT_LOGLEVEL.
+// If the level is not valid it reports false and returns slog.LevelInfo, which
+// is also the level used if none is configured.
+func parseLogLevel(s string) (slog.Level, bool) {
+	switch strings.ToLower(s) {
+	case "trace":
+		return slog.LevelDebug - 4, true
+	case "warning":
+		return slog.LevelWarn, true
+	case "fatal", "panic":
+		return slog.LevelError, true
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, false
+	}
+	return level, true
+}
@@ -6,6 +6,7 @@ package main
 
 import (
 	"archive/zip"
+	"context"
 	"flag"
 	"fmt"
 	"io"
@@ -17,10 +18,9 @@ import (
 	"mellium.im/cli"
 )
 
-func pasteCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
+func pasteCmd(ctx context.Context, inst *sourcehut.Instance) (*cli.Command, error) {
 	client, err := paste.NewClient(
-		paste.SrhtClient(srhtClient),
-		paste.Base(env.paste),
+		paste.Instance(inst),
 	)
 	if err != nil {
 		return nil, err
@@ -30,10 +30,10 @@ func pasteCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 		Usage:       "paste <command> [options]",
 		Description: "Create or download pastes.",
 		Commands: []*cli.Command{
-			getBlob(client),
-			getPasteCmd(client),
-			listPasteCmd(client),
-			pasteVersionCmd(client),
+			getBlob(ctx, client),
+			getPasteCmd(ctx, client),
+			listPasteCmd(ctx, client),
+			pasteVersionCmd(ctx, client),
 		},
 		Run: func(c *cli.Command, _ ...string) error {
 			c.Help()
@@ -42,7 +42,7 @@ func pasteCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 	}, nil
 }
 
-func getBlob(client *paste.Client) *cli.Command {
+func getBlob(ctx context.Context, client *paste.Client) *cli.Command {
 	var (
 		treeName string
 		zipName  string
@@ -72,17 +72,17 @@ file with the same name already exists, it will be truncated.
 				return nil
 			}
 
-			return getBlobs(client, treeName, zipName, ids...)
+			return getBlobs(ctx, client, treeName, zipName, ids...)
 		},
 	}
 }
 
-func pasteVersionCmd(client *paste.Client) *cli.Command {
+func pasteVersionCmd(ctx context.Context, client *paste.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "version",
 		Description: "Shows the version of the paste endpoint.",
 		Run: func(c *cli.Command, ids ...string) error {
-			ver, err := client.Version()
+			ver, err := client.Version(ctx)
 			if err != nil {
 				return err
 			}
@@ -92,12 +92,12 @@ func pasteVersionCmd(client *paste.Client) *cli.Command {
 	}
 }
 
-func listPasteCmd(client *paste.Client) *cli.Command {
+func listPasteCmd(ctx context.Context, client *paste.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "list",
 		Description: "List pastes owned by the authenticated user.",
 		Run: func(c *cli.Command, ids ...string) error {
-			iter, err := client.List()
+			iter, err := client.List(ctx)
 			if err != nil {
 				return err
 			}
@@ -112,7 +112,7 @@ func listPasteCmd(client *paste.Client) *cli.Command {
 	}
 }
 
-func getPasteCmd(client *paste.Client) *cli.Command {
+func getPasteCmd(ctx context.Context, client *paste.Client) *cli.Command {
 	var (
 		treeName string
 		zipName  string
@@ -138,7 +138,7 @@ func getPasteCmd(client *paste.Client) *cli.Command {
 			}
 
 			for _, id := range ids {
-				paste, err := client.Get(id)
+				paste, err := client.Get(ctx, id)
 				if err != nil {
 					// TODO: should this exit immediately, finish but return a non-zero
 					// status, etc?
@@ -151,7 +151,7 @@ func getPasteCmd(client *paste.Client) *cli.Command {
 					for _, f := range paste.Files {
 						ids = append(ids, f.ID)
 					}
-					return getBlobs(client, treeName, zipName, ids...)
+					return getBlobs(ctx, client, treeName, zipName, ids...)
 				}
 
 				// If we're not saving the paste, just print it and don't bother looking
@@ -166,7 +166,7 @@ func getPasteCmd(client *paste.Client) *cli.Command {
 
 // TODO: support downloading with the correct (sanitized) name if available.
 
-func getBlobs(client *paste.Client, treeName, zipName string, ids ...string) error {
+func getBlobs(ctx context.Context, client *paste.Client, treeName, zipName string, ids ...string) error {
 	// Create a zip file if -o was specified.
 	var zipWriter *zip.Writer
 	if zipName != "" {
@@ -181,7 +181,7 @@ func getBlobs(client *paste.Client, treeName, zipName string, ids ...string) err
 	}
 
 	for _, id := range ids {
-		blob, err := client.GetBlob(id)
+		blob, err := client.GetBlob(ctx, id)
 		if err != nil {
 			// TODO: should this exit immediately, finish but return a non-zero
 			// status, etc?
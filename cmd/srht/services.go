@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"mellium.im/cli"
+)
+
+func servicesCmd(ctx context.Context, w io.Writer, inst *sourcehut.Instance) *cli.Command {
+	return &cli.Command{
+		Usage:       "services",
+		Description: "Show which services are available on the instance.",
+		Run: func(c *cli.Command, _ ...string) error {
+			for _, s := range inst.Probe(ctx) {
+				if !s.Available() {
+					fmt.Fprintf(w, "%-6s %s unavailable: %v\n", s.Service, s.BaseURL, s.Err)
+					continue
+				}
+				fmt.Fprintf(w, "%-6s %s %s\n", s.Service, s.BaseURL, s.Version)
+			}
+			return nil
+		},
+	}
+}
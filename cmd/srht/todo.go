@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -13,10 +14,9 @@ import (
 	"mellium.im/cli"
 )
 
-func todoCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
+func todoCmd(ctx context.Context, inst *sourcehut.Instance) (*cli.Command, error) {
 	client, err := todo.NewClient(
-		todo.SrhtClient(srhtClient),
-		todo.Base(env.todo),
+		todo.Instance(inst),
 	)
 	if err != nil {
 		return nil, err
@@ -26,9 +26,9 @@ func todoCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 		Usage:       "todo <command> [options]",
 		Description: "Manipulate issue trackers.",
 		Commands: []*cli.Command{
-			getTODOUserCmd(client),
-			listTrackersCmd(client),
-			todoVersionCmd(client),
+			getTODOUserCmd(ctx, client),
+			listTrackersCmd(ctx, client),
+			todoVersionCmd(ctx, client),
 		},
 		Run: func(c *cli.Command, _ ...string) error {
 			c.Help()
@@ -37,7 +37,7 @@ func todoCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 	}, nil
 }
 
-func getTODOUserCmd(client *todo.Client) *cli.Command {
+func getTODOUserCmd(ctx context.Context, client *todo.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "user [username]",
 		Description: `Show the named or authenticated user's profile.`,
@@ -51,7 +51,7 @@ func getTODOUserCmd(client *todo.Client) *cli.Command {
 				c.Help()
 				return nil
 			}
-			user, err := client.GetUser(username)
+			user, err := client.GetUser(ctx, username)
 			if err != nil {
 				return err
 			}
@@ -62,12 +62,12 @@ func getTODOUserCmd(client *todo.Client) *cli.Command {
 	}
 }
 
-func todoVersionCmd(client *todo.Client) *cli.Command {
+func todoVersionCmd(ctx context.Context, client *todo.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "version",
 		Description: "Shows the version of the todo endpoint.",
 		Run: func(c *cli.Command, ids ...string) error {
-			ver, err := client.Version()
+			ver, err := client.Version(ctx)
 			if err != nil {
 				return err
 			}
@@ -77,7 +77,7 @@ func todoVersionCmd(client *todo.Client) *cli.Command {
 	}
 }
 
-func listTrackersCmd(client *todo.Client) *cli.Command {
+func listTrackersCmd(ctx context.Context, client *todo.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "trackers [username] [tracker]",
 		Description: "List issue trackers owned by the given username or the authenticated user.",
@@ -101,7 +101,7 @@ func listTrackersCmd(client *todo.Client) *cli.Command {
 			}
 
 			if trackerName == "" {
-				trackers, err := client.Trackers(user)
+				trackers, err := client.Trackers(ctx, user)
 				if err != nil {
 					return err
 				}
@@ -112,7 +112,7 @@ func listTrackersCmd(client *todo.Client) *cli.Command {
 				return trackers.Err()
 			}
 
-			tracker, err := client.Tracker(user, trackerName)
+			tracker, err := client.Tracker(ctx, user, trackerName)
 			if err != nil {
 				return err
 			}
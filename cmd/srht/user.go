@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"git.sr.ht/~wombelix/sourcehut-go"
@@ -12,10 +13,9 @@ import (
 	"mellium.im/cli"
 )
 
-func userCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
+func userCmd(ctx context.Context, inst *sourcehut.Instance) (*cli.Command, error) {
 	client, err := meta.NewClient(
-		meta.SrhtClient(srhtClient),
-		meta.Base(env.meta),
+		meta.Instance(inst),
 	)
 	if err != nil {
 		return nil, err
@@ -25,9 +25,9 @@ func userCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 		Usage:       "user <command> [options]",
 		Description: "Get account information.",
 		Commands: []*cli.Command{
-			getUserCmd(client),
-			listAuditLogsCmd(client),
-			metaVersionCmd(client),
+			getUserCmd(ctx, client),
+			listAuditLogsCmd(ctx, client),
+			metaVersionCmd(ctx, client),
 		},
 		Run: func(c *cli.Command, _ ...string) error {
 			c.Help()
@@ -36,12 +36,12 @@ func userCmd(srhtClient sourcehut.Client, env envVars) (*cli.Command, error) {
 	}, nil
 }
 
-func getUserCmd(client *meta.Client) *cli.Command {
+func getUserCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "get",
 		Description: `Show the authenticated users profile.`,
 		Run: func(c *cli.Command, _ ...string) error {
-			user, err := client.GetUser()
+			user, err := client.GetUser(ctx)
 			if err != nil {
 				return err
 			}
@@ -52,12 +52,12 @@ func getUserCmd(client *meta.Client) *cli.Command {
 	}
 }
 
-func metaVersionCmd(client *meta.Client) *cli.Command {
+func metaVersionCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "version",
 		Description: "Shows the version of the meta endpoint.",
 		Run: func(c *cli.Command, ids ...string) error {
-			ver, err := client.Version()
+			ver, err := client.Version(ctx)
 			if err != nil {
 				return err
 			}
@@ -67,7 +67,7 @@ func metaVersionCmd(client *meta.Client) *cli.Command {
 	}
 }
 
-func listAuditLogsCmd(client *meta.Client) *cli.Command {
+func listAuditLogsCmd(ctx context.Context, client *meta.Client) *cli.Command {
 	return &cli.Command{
 		Usage:       "log",
 		Description: `Lists audit logs.`,
@@ -77,7 +77,7 @@ func listAuditLogsCmd(client *meta.Client) *cli.Command {
 				return errWrongArgs
 			}
 
-			iter, err := client.ListAuditLog()
+			iter, err := client.ListAuditLog(ctx)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,297 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// StrictDecoding returns an option that makes the client reject API responses
+// that contain fields which are not part of the type they are decoded into.
+// The response is still decoded, but the call returns an *UnknownFieldsError.
+// If unspecified, unknown fields are ignored.
+//
+// Strict decoding is meant for tests and development where a change to the API
+// should fail loudly; most programs should use ReportUnknownFields instead.
+func StrictDecoding() Option {
+	return func(t *Transport) {
+		t.fields.strict = true
+	}
+}
+
+// ReportUnknownFields returns an option that records fields in API responses
+// that are not part of the type they are decoded into in r, without failing
+// the call.
+func ReportUnknownFields(r *FieldReport) Option {
+	return func(t *Transport) {
+		t.fields.report = r
+	}
+}
+
+// fieldCheck looks for unknown fields in responses if it is enabled by the
+// StrictDecoding or ReportUnknownFields options.
+type fieldCheck struct {
+	strict bool
+	report *FieldReport
+}
+
+func (f fieldCheck) enabled() bool {
+	return f.strict || f.report != nil
+}
+
+// check reports the fields in data that were not decoded into v.
+func (f fieldCheck) check(data []byte, v interface{}) error {
+	if !f.enabled() {
+		return nil
+	}
+	unknown := UnknownFields(data, v)
+	if len(unknown) == 0 {
+		return nil
+	}
+	if f.report != nil {
+		f.report.add(unknown)
+	}
+	if f.strict {
+		return &UnknownFieldsError{Fields: unknown}
+	}
+	return nil
+}
+
+// UnknownFieldsError is returned by clients configured with StrictDecoding
+// when a response contains unknown fields.
+type UnknownFieldsError struct {
+	// Fields maps the names of Go types to the sorted names of the JSON fields
+	// that were found in objects decoded into them but that they don't have.
+	Fields map[string][]string
+}
+
+// Error satisfies the error interface for UnknownFieldsError.
+func (err *UnknownFieldsError) Error() string {
+	return "unknown fields in response: " + formatFields(err.Fields, "; ")
+}
+
+// FieldReport collects the unknown fields found by clients configured with
+// ReportUnknownFields.
+// The zero value is an empty report that is ready to use.
+// A FieldReport is safe for concurrent use.
+type FieldReport struct {
+	mu     sync.Mutex
+	fields map[string]map[string]struct{}
+}
+
+func (r *FieldReport) add(fields map[string][]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fields == nil {
+		r.fields = make(map[string]map[string]struct{})
+	}
+	for typ, names := range fields {
+		set, ok := r.fields[typ]
+		if !ok {
+			set = make(map[string]struct{})
+			r.fields[typ] = set
+		}
+		for _, name := range names {
+			set[name] = struct{}{}
+		}
+	}
+}
+
+// Fields returns the unknown fields that have been found so far, as a map from
+// the names of Go types to the sorted names of the JSON fields that they don't
+// have.
+func (r *FieldReport) Fields() map[string][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fields := make(map[string][]string, len(r.fields))
+	for typ, set := range r.fields {
+		for name := range set {
+			fields[typ] = append(fields[typ], name)
+		}
+		slices.Sort(fields[typ])
+	}
+	return fields
+}
+
+// String returns the report with one line per type, sorted by type name.
+func (r *FieldReport) String() string {
+	return formatFields(r.Fields(), "\n")
+}
+
+func formatFields(fields map[string][]string, sep string) string {
+	types := make([]string, 0, len(fields))
+	for typ := range fields {
+		types = append(types, typ)
+	}
+	slices.Sort(types)
+	lines := make([]string, 0, len(types))
+	for _, typ := range types {
+		lines = append(lines, fmt.Sprintf("%s: %s", typ, strings.Join(fields[typ], ", ")))
+	}
+	return strings.Join(lines, sep)
+}
+
+// UnknownFields returns the fields in the JSON document data that would be
+// ignored if it were decoded into v using encoding/json, as a map from the
+// names of Go types to the sorted names of the JSON fields that they don't
+// have.
+// Anonymous struct types are named after the field that contains them, eg.
+// "git.Commit.signature".
+// It returns nil if there are no unknown fields or data is not valid JSON.
+//
+// Types that implement json.Unmarshaler or encoding.TextUnmarshaler are
+// assumed to handle all of their fields.
+func UnknownFields(data []byte, v interface{}) map[string][]string {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+	// Values decoded into a pointer to an interface are decoded into whatever
+	// the interface holds.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && !rv.IsNil() &&
+		rv.Elem().Kind() == reflect.Interface && !rv.Elem().IsNil() {
+		t = rv.Elem().Elem().Type()
+	}
+
+	unknown := make(map[string][]string)
+	walkFields(doc, t, "", unknown)
+	if len(unknown) == 0 {
+		return nil
+	}
+	for typ := range unknown {
+		slices.Sort(unknown[typ])
+		unknown[typ] = slices.Compact(unknown[typ])
+	}
+	return unknown
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// walkFields records the keys of objects in doc that have no corresponding
+// field in t.
+// The name is used for anonymous struct types.
+func walkFields(doc interface{}, t reflect.Type, name string, unknown map[string][]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		name = t.String()
+	}
+	pt := reflect.PointerTo(t)
+	if pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := structFields(t)
+		for k, v := range obj {
+			ft, ok := fields.lookup(k)
+			if !ok {
+				unknown[name] = append(unknown[name], k)
+				continue
+			}
+			walkFields(v, ft, name+"."+k, unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		arr, ok := doc.([]interface{})
+		if !ok {
+			return
+		}
+		for _, v := range arr {
+			walkFields(v, t.Elem(), name, unknown)
+		}
+	case reflect.Map:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, v := range obj {
+			walkFields(v, t.Elem(), name, unknown)
+		}
+	}
+}
+
+// jsonFields maps the JSON names of the fields of a struct to their types.
+type jsonFields map[string]reflect.Type
+
+// lookup finds a field in the same way as encoding/json, preferring an exact
+// match but falling back to a case-insensitive one.
+func (f jsonFields) lookup(key string) (reflect.Type, bool) {
+	if t, ok := f[key]; ok {
+		return t, true
+	}
+	for name, t := range f {
+		if strings.EqualFold(name, key) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+var fieldCache sync.Map // map[reflect.Type]jsonFields
+
+func structFields(t reflect.Type) jsonFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(jsonFields)
+	}
+	fields := make(jsonFields)
+	addStructFields(fields, t)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func addStructFields(fields jsonFields, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				// Fields of embedded structs are promoted, but the embedding struct's
+				// own fields take precedence.
+				embedded := make(jsonFields)
+				addStructFields(embedded, ft)
+				for k, v := range embedded {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+}
@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+)
+
+type decodeItem struct {
+	sourcehut.ShortUser
+
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created"`
+	Tags    []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+	Owner  *sourcehut.ShortUser           `json:"owner"`
+	ByName map[string]sourcehut.ShortUser `json:"by_name"`
+	Extra  interface{}                    `json:"extra"`
+	Skip   string                         `json:"-"`
+	Plain  string
+}
+
+var unknownFieldsTests = [...]struct {
+	in      string
+	v       interface{}
+	unknown map[string][]string
+}{
+	0: {in: `{"id": 1, "name": "x", "canonical_name": "~x", "Plain": "", "extra": {"any": 1}}`, v: &decodeItem{}},
+	1: {in: `{"ID": 1, "plain": "case insensitive", "created": "2026-01-01T00:00:00Z"}`, v: &decodeItem{}},
+	2: {
+		in: `{"id": 1, "new": true, "Skip": "", "tags": [{"name": "a", "color": "red"}, {"name": "b", "color": "blue"}]}`,
+		v:  &decodeItem{},
+		unknown: map[string][]string{
+			"sourcehut_test.decodeItem":      {"Skip", "new"},
+			"sourcehut_test.decodeItem.tags": {"color"},
+		},
+	},
+	3: {
+		in: `{"owner": {"name": "x", "email": "x@example.org"}, "by_name": {"x": {"name": "x", "url": ""}}}`,
+		v:  &decodeItem{},
+		unknown: map[string][]string{
+			"sourcehut.ShortUser": {"email", "url"},
+		},
+	},
+	4: {
+		in:      `[{"id": 1, "bio": ""}, {"id": 2}]`,
+		v:       &[]*decodeItem{},
+		unknown: map[string][]string{"sourcehut_test.decodeItem": {"bio"}},
+	},
+	5: {
+		in:      `{"id": 1, "bio": ""}`,
+		v:       func() interface{} { var v interface{} = &decodeItem{}; return &v }(),
+		unknown: map[string][]string{"sourcehut_test.decodeItem": {"bio"}},
+	},
+	6: {in: `{"id": 1, "bio": ""}`, v: &map[string]interface{}{}},
+	7: {in: `{"id": `, v: &decodeItem{}},
+}
+
+func TestUnknownFields(t *testing.T) {
+	for i, tc := range unknownFieldsTests {
+		unknown := sourcehut.UnknownFields([]byte(tc.in), tc.v)
+		if !reflect.DeepEqual(unknown, tc.unknown) {
+			t.Errorf("%d: wrong unknown fields: want=%v, got=%v", i, tc.unknown, unknown)
+		}
+	}
+}
+
+func newDecodeServer(t *testing.T) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/list" {
+			_, _ = w.Write([]byte(`{"results": [{"id": 1, "new": 1}, {"id": 2, "other": 2}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 1, "name": "x", "new": true}`))
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStrictDecoding(t *testing.T) {
+	server := newDecodeServer(t)
+	for _, strict := range []bool{false, true} {
+		opts := []sourcehut.Option{
+			sourcehut.Token("token"),
+			sourcehut.UserAgent("test"),
+			sourcehut.RoundTripper(server.Client().Transport),
+		}
+		if strict {
+			opts = append(opts, sourcehut.StrictDecoding())
+		}
+		client := sourcehut.NewClient(opts...)
+
+		req, err := http.NewRequest("GET", server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var item decodeItem
+		_, err = client.Do(req, &item)
+		if item.ID != 1 || item.Name != "x" {
+			t.Errorf("strict=%t: response was not decoded: %+v", strict, item)
+		}
+		var fieldsErr *sourcehut.UnknownFieldsError
+		switch {
+		case !strict && err != nil:
+			t.Errorf("Unexpected error when lenient: %v", err)
+		case strict && !errors.As(err, &fieldsErr):
+			t.Errorf("Expected unknown fields error when strict, got: %v", err)
+		case strict:
+			const msg = "unknown fields in response: sourcehut_test.decodeItem: new"
+			if err.Error() != msg {
+				t.Errorf("Wrong error message: want=%q, got=%q", msg, err.Error())
+			}
+		}
+
+		req, err = http.NewRequest("GET", server.URL+"/list", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		iter := sourcehut.List[decodeItem](client, req).Stream()
+		var n int
+		for iter.Next() {
+			n++
+		}
+		switch {
+		case !strict && (iter.Err() != nil || n != 2):
+			t.Errorf("Expected 2 items when lenient, got %d and err: %v", n, iter.Err())
+		case strict && (!errors.As(iter.Err(), &fieldsErr) || n != 0):
+			t.Errorf("Expected unknown fields error on first item when strict, got %d items and err: %v", n, iter.Err())
+		}
+	}
+}
+
+func TestReportUnknownFields(t *testing.T) {
+	server := newDecodeServer(t)
+	report := &sourcehut.FieldReport{}
+	client := sourcehut.NewClient(
+		sourcehut.Token("token"),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+		sourcehut.ReportUnknownFields(report),
+	)
+
+	req, err := http.NewRequest("GET", server.URL+"/list", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for _, err := range sourcehut.List[*decodeItem](client, req).All() {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 items, got %d", n)
+	}
+	req, err = http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Do(req, &decodeItem{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string][]string{"sourcehut_test.decodeItem": {"new", "other"}}
+	if fields := report.Fields(); !reflect.DeepEqual(fields, want) {
+		t.Errorf("Wrong report: want=%v, got=%v", want, fields)
+	}
+	const s = "sourcehut_test.decodeItem: new, other"
+	if report.String() != s {
+		t.Errorf("Wrong report string: want=%q, got=%q", s, report.String())
+	}
+}
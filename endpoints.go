@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut
+
+import (
+	"context"
+)
+
+// Endpoints describes the endpoints of a service for the service clients.
+// Each service package keeps one to identify its calls in the CallInfo of
+// requests and to check whether they need authentication.
+type Endpoints struct {
+	// Service is the name of the service, eg. ServiceGit.
+	Service string
+
+	// Public lists the endpoints that anonymous clients can read with GET
+	// requests, using the placeholders of CallInfo.Endpoint.
+	Public map[string]bool
+}
+
+// Call returns a copy of ctx that identifies requests made with it as calls to
+// the provided endpoint of the service.
+func (e Endpoints) Call(ctx context.Context, endpoint string) context.Context {
+	return WithCallInfo(ctx, CallInfo{Service: e.Service, Endpoint: endpoint})
+}
+
+// UserCall is like Call for the "user/{username}" endpoint that several
+// services provide, which returns the authenticated user (and is called
+// "user") if username is empty.
+func (e Endpoints) UserCall(ctx context.Context, username string) context.Context {
+	if username == "" {
+		return e.Call(ctx, "user")
+	}
+	return e.Call(ctx, "user/{username}")
+}
+
+// Authorize returns an *AuthRequiredError if c is anonymous and the call in
+// ctx, made with the provided HTTP method, requires authentication.
+func (e Endpoints) Authorize(ctx context.Context, c Client, method string) error {
+	info, _ := CallInfoFromContext(ctx)
+	if method == "GET" && e.Public[info.Endpoint] {
+		return nil
+	}
+	return c.RequireAuth(ctx)
+}
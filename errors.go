@@ -4,21 +4,155 @@
 
 package sourcehut
 
-import "strings"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
-// Ensure that the build fails if Error and Errors don't implement error.
-var _, _ error = (*Error)(nil), (*Errors)(nil)
+// Ensure that the build fails if Error, Errors, HTTPError, AuthRequiredError,
+// and UnknownFieldsError don't implement error.
+var _, _, _, _, _ error = (*Error)(nil), (*Errors)(nil), (*HTTPError)(nil), (*AuthRequiredError)(nil), (*UnknownFieldsError)(nil)
+
+// Sentinel errors that classify failed API calls.
+// Errors returned by the API match these using errors.Is based on the HTTP
+// status code of the response.
+var (
+	// ErrNotFound matches errors from responses with status 404 Not Found.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized matches errors from responses with status 401
+	// Unauthorized, usually caused by a missing or invalid access token.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden matches errors from responses with status 403 Forbidden.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrRateLimited matches errors from responses with status 429 Too Many
+	// Requests, and the errors returned when a request would exceed the client
+	// side rate limit configured with the RateLimit option.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrValidation matches errors from responses with status 400 Bad Request
+	// or 422 Unprocessable Entity, which the API uses to reject invalid input.
+	ErrValidation = errors.New("validation failed")
+)
+
+// ErrAuthRequired matches errors returned by anonymous clients from methods
+// that require authentication.
+var ErrAuthRequired = errors.New("authentication required")
+
+// AuthRequiredError is returned by anonymous clients when a call requires
+// authentication.
+// It matches both ErrAuthRequired and ErrUnauthorized using errors.Is.
+type AuthRequiredError struct {
+	Service  string
+	Endpoint string
+}
+
+// Error satisfies the error interface for AuthRequiredError.
+func (err *AuthRequiredError) Error() string {
+	return fmt.Sprintf("%s %s requires authentication, but the client is anonymous", err.Service, err.Endpoint)
+}
+
+// Is reports whether target is ErrAuthRequired or ErrUnauthorized.
+func (err *AuthRequiredError) Is(target error) bool {
+	return target == ErrAuthRequired || target == ErrUnauthorized
+}
+
+// IsNotFound reports whether err indicates that the requested resource does
+// not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized reports whether err indicates that the request was not
+// authenticated.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden reports whether err indicates that the authenticated user is not
+// allowed to perform the request.
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsRateLimited reports whether err indicates that the request was rejected by
+// the server's rate limit or by the client side rate limit configured with the
+// RateLimit option.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsValidation reports whether err indicates that the request was rejected
+// because of invalid input.
+// The field that caused the failure, if any, is available on the Error.
+func IsValidation(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
+// statusIs reports whether the provided status code matches target.
+func statusIs(code int, target error) bool {
+	switch target {
+	case ErrNotFound:
+		return code == http.StatusNotFound
+	case ErrUnauthorized:
+		return code == http.StatusUnauthorized
+	case ErrForbidden:
+		return code == http.StatusForbidden
+	case ErrRateLimited:
+		return code == http.StatusTooManyRequests
+	case ErrValidation:
+		return code == http.StatusBadRequest || code == http.StatusUnprocessableEntity
+	}
+	return false
+}
 
 // Error represents an individual error returned from a Sourcehut API call.
 //
+// Errors returned by the GraphQL API use their message as the Reason and may
+// also contain the path of the field that caused the error and additional
+// implementation specific details in Extensions.
+//
 // API docs: https://man.sr.ht/api-conventions.md#error-responses
 type Error struct {
-	Field  string
-	Reason string
+	Field      string
+	Reason     string
+	Path       []interface{}
+	Extensions map[string]interface{}
 
 	statusCode int
 }
 
+// UnmarshalJSON satisfies the json.Unmarshaler interface for Error.
+// It accepts both the legacy REST error format and the GraphQL error format.
+func (err *Error) UnmarshalJSON(b []byte) error {
+	var e struct {
+		Field      string                 `json:"field"`
+		Reason     string                 `json:"reason"`
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	}
+	if jsonErr := json.Unmarshal(b, &e); jsonErr != nil {
+		return jsonErr
+	}
+	err.Field = e.Field
+	err.Reason = e.Reason
+	if err.Reason == "" {
+		err.Reason = e.Message
+	}
+	if field, ok := e.Extensions["field"].(string); ok && err.Field == "" {
+		err.Field = field
+	}
+	err.Path = e.Path
+	err.Extensions = e.Extensions
+	return nil
+}
+
 // StatusCode returns the HTTP status code of the request that unmarshaled this
 // error.
 // May not be set for Errors originating from code outside this package.
@@ -31,6 +165,12 @@ func (err Error) Error() string {
 	return err.Reason
 }
 
+// Is reports whether the error matches target, which may be one of the sentinel
+// errors such as ErrNotFound.
+func (err Error) Is(target error) bool {
+	return statusIs(err.statusCode, target)
+}
+
 // Errors is a slice of Error's that itself implements error.
 type Errors []Error
 
@@ -59,3 +199,47 @@ func (err Errors) StatusCode() int {
 	}
 	return err[0].statusCode
 }
+
+// Is reports whether the errors match target, which may be one of the sentinel
+// errors such as ErrNotFound.
+func (err Errors) Is(target error) bool {
+	return statusIs(err.StatusCode(), target)
+}
+
+// Field returns the errors that were caused by the named field, or nil if there
+// are none.
+func (err Errors) Field(name string) Errors {
+	var fieldErrs Errors
+	for _, e := range err {
+		if e.Field == name {
+			fieldErrs = append(fieldErrs, e)
+		}
+	}
+	return fieldErrs
+}
+
+// HTTPError is returned when the API responds with an error status code but
+// the response body does not contain any API errors, for example because it is
+// not JSON.
+type HTTPError struct {
+	// Body is the raw body of the response.
+	Body []byte
+
+	statusCode int
+}
+
+// StatusCode returns the HTTP status code of the response.
+func (err HTTPError) StatusCode() int {
+	return err.statusCode
+}
+
+// Error satisfies the error interface for HTTPError.
+func (err HTTPError) Error() string {
+	return fmt.Sprintf("unexpected response status %d %s", err.statusCode, http.StatusText(err.statusCode))
+}
+
+// Is reports whether the error matches target, which may be one of the sentinel
+// errors such as ErrNotFound.
+func (err HTTPError) Is(target error) bool {
+	return statusIs(err.statusCode, target)
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+)
+
+type errorTest struct {
+	code int
+	body string
+	is   []error
+}
+
+var errorTests = [...]errorTest{
+	0: {code: 404, body: `{"errors": [{"reason": "Not found"}]}`, is: []error{sourcehut.ErrNotFound}},
+	1: {code: 404, body: `<html>Not found</html>`, is: []error{sourcehut.ErrNotFound}},
+	2: {code: 401, body: `{"errors": []}`, is: []error{sourcehut.ErrUnauthorized}},
+	3: {code: 403, body: `{"errors": [{"reason": "a"}, {"reason": "b"}]}`, is: []error{sourcehut.ErrForbidden}},
+	4: {code: 429, is: []error{sourcehut.ErrRateLimited}},
+	5: {code: 400, body: `{"errors": [{"field": "name", "reason": "Required"}]}`, is: []error{sourcehut.ErrValidation}},
+	6: {code: 422, body: `{"errors": [{"field": "name", "reason": "Too long"}]}`, is: []error{sourcehut.ErrValidation}},
+	7: {code: 500, body: `Internal Server Error`},
+}
+
+var sentinelErrors = []error{
+	sourcehut.ErrNotFound,
+	sourcehut.ErrUnauthorized,
+	sourcehut.ErrForbidden,
+	sourcehut.ErrRateLimited,
+	sourcehut.ErrValidation,
+}
+
+func TestErrorClassification(t *testing.T) {
+	for i, tc := range errorTests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(tc.code)
+				_, err := w.Write([]byte(tc.body))
+				if err != nil {
+					t.Fatalf("Error writing response body (this should never happen): %q", err)
+				}
+			}))
+			server.Config.ErrorLog = testlog.New(t)
+			server.Start()
+			defer server.Close()
+
+			req, err := http.NewRequest("GET", server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = sourcehut.NewBaseClient(server.Client()).Do(req, nil)
+			if err == nil {
+				t.Fatalf("Expected error for status %d", tc.code)
+			}
+			var sc interface{ StatusCode() int }
+			if !errors.As(err, &sc) || sc.StatusCode() != tc.code {
+				t.Errorf("Status code not preserved: want=%d, got=%v", tc.code, sc)
+			}
+		outer:
+			for _, sentinel := range sentinelErrors {
+				for _, want := range tc.is {
+					if sentinel == want {
+						if !errors.Is(err, sentinel) {
+							t.Errorf("Expected error %v to match %v", err, sentinel)
+						}
+						continue outer
+					}
+				}
+				if errors.Is(err, sentinel) {
+					t.Errorf("Error %v unexpectedly matched %v", err, sentinel)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPErrorBody(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, err := w.Write([]byte("bad gateway"))
+		if err != nil {
+			t.Fatalf("Error writing response body (this should never happen): %q", err)
+		}
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = sourcehut.NewBaseClient(server.Client()).Do(req, nil)
+	var httpErr sourcehut.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Unexpected error type %T: %v", err, err)
+	}
+	if string(httpErr.Body) != "bad gateway" {
+		t.Errorf("Unexpected body: want=%q, got=%q", "bad gateway", httpErr.Body)
+	}
+}
+
+func TestErrorsField(t *testing.T) {
+	errs := sourcehut.Errors{
+		{Field: "name", Reason: "Required"},
+		{Field: "description", Reason: "Too long"},
+		{Field: "name", Reason: "Invalid"},
+	}
+	if f := errs.Field("name"); len(f) != 2 || f[0].Reason != "Required" || f[1].Reason != "Invalid" {
+		t.Errorf("Unexpected errors for field name: %v", f)
+	}
+	if f := errs.Field("visibility"); f != nil {
+		t.Errorf("Unexpected errors for field visibility: %v", f)
+	}
+}
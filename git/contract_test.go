@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package git_test
+
+import (
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/contract"
+)
+
+func TestContract(t *testing.T) {
+	contract.Check(t, "testdata/contract.json", map[string]func() interface{}{
+		"Repo":   func() interface{} { return &git.Repo{} },
+		"Commit": func() interface{} { return &git.Commit{} },
+		"Author": func() interface{} { return &git.Author{} },
+		"Tree":   func() interface{} { return &git.Tree{} },
+		"Ref":    func() interface{} { return &git.Ref{} },
+	})
+}
@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package git_test
+
+import (
+	"context"
+	"log"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+)
+
+func ExampleRepoIter() {
+	srhtClient := sourcehut.NewClient(sourcehut.Token("<personal access token>"))
+	gitClient, _ := git.NewClient(git.SrhtClient(srhtClient))
+
+	repos, err := gitClient.Repos(context.Background(), "~sircmpwn")
+	if err != nil {
+		log.Fatalf("Error listing repos: %q", err)
+	}
+	for repo, err := range repos.All() {
+		if err != nil {
+			log.Fatalf("Error fetching repos: %q", err)
+		}
+		log.Printf("Repo %s: %s\n", repo.Name, repo.Description)
+	}
+}
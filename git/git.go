@@ -7,6 +7,7 @@ package git
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,6 +23,18 @@ import (
 // It is exported for convenience.
 const BaseURL = "https://git.sr.ht/api/"
 
+// endpoints identifies the calls made by the client and lists the endpoints
+// that anonymous clients can read.
+var endpoints = sourcehut.Endpoints{
+	Service: sourcehut.ServiceGit,
+	Public: map[string]bool{
+		"version":                 true,
+		"user/{username}":         true,
+		"{username}/repos":        true,
+		"{username}/repos/{name}": true,
+	},
+}
+
 // Option is used to configure an API client.
 type Option func(*Client) error
 
@@ -57,6 +70,17 @@ func Base(base string) Option {
 	}
 }
 
+// Instance returns an option that configures the client to use the git API
+// of the provided Sourcehut instance and the sourcehut.Client that the instance
+// was created with.
+// Options that follow it, such as Base, override its settings.
+func Instance(inst *sourcehut.Instance) Option {
+	return func(c *Client) error {
+		c.srhtClient = inst.Client()
+		return Base(inst.BaseURL(endpoints.Service))(c)
+	}
+}
+
 // Client handles communication with the Sourcehut API.
 //
 // API docs: https://man.sr.ht/git.sr.ht/api.md
@@ -91,26 +115,28 @@ func NewClient(opts ...Option) (*Client, error) {
 // Version returns the version of the API.
 //
 // API docs: https://man.sr.ht/api-conventions.md#get-apiversion
-func (c *Client) Version() (string, error) {
+func (c *Client) Version(ctx context.Context) (string, error) {
+	ctx = endpoints.Call(ctx, "version")
 	var ver struct {
 		Version string `json:"version"`
 	}
-	_, err := c.do("GET", "version", "", nil, &ver)
+	_, err := c.do(ctx, "GET", "version", "", nil, &ver)
 	return ver.Version, err
 }
 
 // Repo returns information about a specific repository owned by the provided
 // username.
 // If an empty username is provided, the authenticated user is used.
-func (c *Client) Repo(username, repo string) (*Repo, error) {
-	p := "repos"
+func (c *Client) Repo(ctx context.Context, username, repo string) (*Repo, error) {
+	p, endpoint := "repos", "repos/{name}"
 	if username != "" {
-		p = url.PathEscape(username) + "/repos"
+		p, endpoint = url.PathEscape(username)+"/repos", "{username}/repos/{name}"
 	}
 	p = path.Join(p, url.PathEscape(repo))
+	ctx = endpoints.Call(ctx, endpoint)
 
 	newRepo := &Repo{}
-	_, err := c.do("GET", p, "", nil, newRepo)
+	_, err := c.do(ctx, "GET", p, "", nil, newRepo)
 	if err != nil {
 		return nil, err
 	}
@@ -119,13 +145,15 @@ func (c *Client) Repo(username, repo string) (*Repo, error) {
 }
 
 // DeleteRepo removes a repository.
-func (c *Client) DeleteRepo(repo string) error {
-	_, err := c.do("DELETE", path.Join("repos", url.PathEscape(repo)), "", nil, nil)
+func (c *Client) DeleteRepo(ctx context.Context, repo string) error {
+	ctx = endpoints.Call(ctx, "repos/{name}")
+	_, err := c.do(ctx, "DELETE", path.Join("repos", url.PathEscape(repo)), "", nil, nil)
 	return err
 }
 
 // NewRepo creates and returns a new repository from the provided template.
-func (c *Client) NewRepo(name, description string, visibility RepoVisibility) (*Repo, error) {
+func (c *Client) NewRepo(ctx context.Context, name, description string, visibility RepoVisibility) (*Repo, error) {
+	ctx = endpoints.Call(ctx, "repos")
 	jsonRepo, err := json.Marshal(struct {
 		Name string `json:"name"`
 		Desc string `json:"description"`
@@ -140,7 +168,7 @@ func (c *Client) NewRepo(name, description string, visibility RepoVisibility) (*
 	}
 
 	newRepo := &Repo{}
-	_, err = c.do("POST", "repos", "application/json", bytes.NewReader(jsonRepo), newRepo)
+	_, err = c.do(ctx, "POST", "repos", "application/json", bytes.NewReader(jsonRepo), newRepo)
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +180,8 @@ func (c *Client) NewRepo(name, description string, visibility RepoVisibility) (*
 //
 // If repo.Name differs from oldName, a redirect from the old name to the new
 // name.
-func (c *Client) UpdateRepo(oldName string, repo *Repo) error {
+func (c *Client) UpdateRepo(ctx context.Context, oldName string, repo *Repo) error {
+	ctx = endpoints.Call(ctx, "repos/{name}")
 	updateData := make(map[string]interface{})
 
 	// Only include name if it's different from oldName (for renaming)
@@ -177,31 +206,34 @@ func (c *Client) UpdateRepo(oldName string, repo *Repo) error {
 	}
 
 	p := path.Join("repos", url.PathEscape(oldName))
-	_, err = c.do("PUT", p, "application/json", bytes.NewReader(jsonRepo), nil)
+	_, err = c.do(ctx, "PUT", p, "application/json", bytes.NewReader(jsonRepo), nil)
 	return err
 }
 
 // Repos returns an iterator over all repos owned by the provided username.
 // If an empty username is provided, the authenticated user is used.
-func (c *Client) Repos(username string) (RepoIter, error) {
-	path := "repos"
+func (c *Client) Repos(ctx context.Context, username string) (RepoIter, error) {
+	path, endpoint := "repos", "repos"
 	if username != "" {
-		path = url.PathEscape(username) + "/repos"
+		path, endpoint = url.PathEscape(username)+"/repos", "{username}/repos"
 	}
-	return c.repos("GET", path, nil)
+	return c.repos(endpoints.Call(ctx, endpoint), "GET", path, nil)
 }
 
 // GetUser returns information about the provided username, or the currently
 // authenticated user if the username is empty.
-func (c *Client) GetUser(username string) (sourcehut.User, error) {
+func (c *Client) GetUser(ctx context.Context, username string) (sourcehut.User, error) {
 	user := sourcehut.User{}
-	_, err := c.do("GET", path.Join("user", username), "", nil, &user)
+	_, err := c.do(endpoints.UserCall(ctx, username), "GET", path.Join("user", username), "", nil, &user)
 	return user, err
 }
 
-func (c *Client) do(method, u, contentType string, body io.Reader, v interface{}) (*http.Response, error) {
+func (c *Client) do(ctx context.Context, method, u, contentType string, body io.Reader, v interface{}) (*http.Response, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return nil, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return nil, err
 	}
@@ -211,14 +243,14 @@ func (c *Client) do(method, u, contentType string, body io.Reader, v interface{}
 	return c.srhtClient.Do(req, v)
 }
 
-func (c *Client) repos(method, u string, body io.Reader) (RepoIter, error) {
+func (c *Client) repos(ctx context.Context, method, u string, body io.Reader) (RepoIter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return RepoIter{}, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return RepoIter{}, err
 	}
-	iter := c.srhtClient.List(req, func() interface{} {
-		return &Repo{}
-	})
-	return RepoIter{Iter: iter}, nil
+	return RepoIter{Iter: sourcehut.List[*Repo](c.srhtClient, req)}, nil
 }
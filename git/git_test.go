@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package git_test
+
+import (
+	"context"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/replaytest"
+)
+
+func newClient(t *testing.T, cassette string) *git.Client {
+	t.Helper()
+	client, err := git.NewClient(git.SrhtClient(replaytest.Client(t, cassette)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRepoLifecycle(t *testing.T) {
+	const name = "sourcehut-go-test"
+	client := newClient(t, "repo_lifecycle")
+	ctx := context.Background()
+
+	repo, err := client.NewRepo(ctx, name, "Created by the sourcehut-go tests", git.VisibilityPublic)
+	if err != nil {
+		t.Fatalf("Error creating repo: %v", err)
+	}
+	if repo.Name != name || repo.Visibility != git.VisibilityPublic {
+		t.Errorf("Unexpected repo: %+v", repo)
+	}
+
+	err = client.UpdateRepo(ctx, name, &git.Repo{
+		Name:        name,
+		Description: "Updated by the sourcehut-go tests",
+		Visibility:  git.VisibilityUnlisted,
+	})
+	if err != nil {
+		t.Fatalf("Error updating repo: %v", err)
+	}
+	repo, err = client.Repo(ctx, "", name)
+	if err != nil {
+		t.Fatalf("Error fetching repo: %v", err)
+	}
+	if repo.Description != "Updated by the sourcehut-go tests" || repo.Visibility != git.VisibilityUnlisted {
+		t.Errorf("Update was not applied: %+v", repo)
+	}
+
+	err = client.DeleteRepo(ctx, name)
+	if err != nil {
+		t.Fatalf("Error deleting repo: %v", err)
+	}
+	_, err = client.Repo(ctx, "", name)
+	if !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error after delete, got: %v", err)
+	}
+}
+
+func TestRepos(t *testing.T) {
+	client := newClient(t, "repos")
+
+	repos, err := client.Repos(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for repo, err := range repos.All() {
+		if err != nil {
+			t.Fatalf("Error listing repos: %v", err)
+		}
+		names = append(names, repo.Name)
+	}
+	want := []string{"one", "two", "three"}
+	if len(names) != len(want) {
+		t.Fatalf("Unexpected repos across pages: want=%v, got=%v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Unexpected repo %d: want=%q, got=%q", i, want[i], names[i])
+		}
+	}
+}
@@ -10,10 +10,10 @@ import (
 
 // RepoIter is used for iterating over a collection of repos.
 type RepoIter struct {
-	*sourcehut.Iter
+	*sourcehut.Iter[*Repo]
 }
 
 // Repo returns the repo which the iterator is currently pointing to.
 func (i RepoIter) Repo() *Repo {
-	return i.Current().(*Repo)
+	return i.Current()
 }
@@ -2,9 +2,4 @@ module git.sr.ht/~wombelix/sourcehut-go
 
 go 1.23
 
-require (
-	github.com/sirupsen/logrus v1.9.3
-	mellium.im/cli v0.1.0
-)
-
-require golang.org/x/sys v0.28.0 // indirect
+require mellium.im/cli v0.1.0
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var errNoData = errors.New("no data in GraphQL response")
+
+// GraphQL handles communication with the GraphQL API of a single Sourcehut
+// service.
+//
+// Requests are sent through the provided Client, so options such as the access
+// token, retry policy, and rate limit apply to GraphQL requests as well.
+//
+// API docs: https://man.sr.ht/graphql.md
+type GraphQL struct {
+	endpoint string
+	c        Client
+}
+
+// NewGraphQL returns a GraphQL client that sends queries to the provided
+// endpoint (eg. "https://git.sr.ht/query") using c.
+func NewGraphQL(c Client, endpoint string) *GraphQL {
+	return &GraphQL{endpoint: endpoint, c: c}
+}
+
+// Query executes a query or mutation with the provided variables and
+// unmarshals the data in the response into v.
+//
+// If the response contains errors they are returned as an Error or Errors.
+// Because GraphQL responses may contain partial results, any data that was
+// returned alongside the errors is still unmarshaled into v.
+func (g *GraphQL) Query(ctx context.Context, query string, vars map[string]interface{}, v interface{}) error {
+	_, data, err := g.query(ctx, query, vars)
+	if v != nil && len(data) > 0 && !bytes.Equal(data, []byte("null")) {
+		jsonErr := json.Unmarshal(data, v)
+		if jsonErr == nil {
+			jsonErr = g.c.fields.check(data, v)
+		}
+		if jsonErr != nil && err == nil {
+			err = jsonErr
+		}
+	}
+	return err
+}
+
+// List returns an iterator over a paginated field in the result of a query.
+//
+// The query must accept a "$cursor: Cursor" variable and pass it to the
+// paginated field, which must select the "cursor" and "results" fields.
+// The paginated field is located using path, a dot separated list of field
+// names or aliases starting from the root of the response data (eg.
+// "me.repositories").
+// Each item in results will be decoded into the value returned from a call to
+// d.
+// If d is nil, a map[string]interface{} is created for each item.
+//
+// No HTTP request will be issued until iteration is started by a call to Next.
+// Once ctx is canceled no further pages will be requested.
+func (g *GraphQL) List(ctx context.Context, query string, vars map[string]interface{}, path string, d func() interface{}) *Iter[interface{}] {
+	return &Iter[interface{}]{ctx: ctx, page: g.page(query, vars, path), into: d, fields: g.c.fields}
+}
+
+// ListGraphQL is like the List method on GraphQL except that it decodes each
+// item into a new value of type T.
+func ListGraphQL[T any](ctx context.Context, g *GraphQL, query string, vars map[string]interface{}, path string) *Iter[T] {
+	return &Iter[T]{ctx: ctx, page: g.page(query, vars, path), fields: g.c.fields}
+}
+
+func (g *GraphQL) page(query string, vars map[string]interface{}, path string) pageFunc {
+	return func(ctx context.Context, cursor string) (*Response, error) {
+		pageVars := make(map[string]interface{}, len(vars)+1)
+		for k, v := range vars {
+			pageVars[k] = v
+		}
+		if cursor != "" {
+			pageVars["cursor"] = cursor
+		}
+
+		resp, data, err := g.query(ctx, query, pageVars)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range strings.Split(path, ".") {
+			var obj map[string]json.RawMessage
+			err = json.Unmarshal(data, &obj)
+			if err != nil {
+				return nil, err
+			}
+			var ok bool
+			data, ok = obj[field]
+			if !ok {
+				return nil, errNoData
+			}
+		}
+
+		var p struct {
+			Cursor  *string         `json:"cursor"`
+			Results json.RawMessage `json:"results"`
+		}
+		err = json.Unmarshal(data, &p)
+		if err != nil {
+			return nil, err
+		}
+		r := &Response{Response: resp, Results: p.Results}
+		if p.Cursor != nil {
+			r.Next = *p.Cursor
+		}
+		return r, nil
+	}
+}
+
+func (g *GraphQL) query(ctx context.Context, query string, vars map[string]interface{}) (*http.Response, json.RawMessage, error) {
+	body, err := json.Marshal(struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}{
+		Query:     query,
+		Variables: vars,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", g.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.c.do(req)
+	if err != nil {
+		return resp, nil, err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors Errors          `json:"errors"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&envelope)
+	if err != nil {
+		return resp, nil, err
+	}
+	switch len(envelope.Errors) {
+	case 0:
+		if len(envelope.Data) == 0 || bytes.Equal(envelope.Data, []byte("null")) {
+			return resp, nil, errNoData
+		}
+		return resp, envelope.Data, nil
+	case 1:
+		envelope.Errors[0].statusCode = resp.StatusCode
+		return resp, envelope.Data, envelope.Errors[0]
+	}
+	for i := 0; i < len(envelope.Errors); i++ {
+		envelope.Errors[i].statusCode = resp.StatusCode
+	}
+	return resp, envelope.Data, envelope.Errors
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+func newGraphQLServer(t *testing.T, h func(graphQLRequest) string) *sourcehut.GraphQL {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			t.Errorf("Unexpected method: want=POST, got=%s", req.Method)
+		}
+		if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Unexpected content type: want=application/json, got=%s", ct)
+		}
+		var q graphQLRequest
+		err := json.NewDecoder(req.Body).Decode(&q)
+		if err != nil {
+			t.Errorf("Error decoding query: %v", err)
+		}
+		_, err = w.Write([]byte(h(q)))
+		if err != nil {
+			t.Errorf("Error writing response body (this should never happen): %q", err)
+		}
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+	return sourcehut.NewGraphQL(sourcehut.NewBaseClient(server.Client()), server.URL+"/query")
+}
+
+func TestGraphQLQuery(t *testing.T) {
+	g := newGraphQLServer(t, func(q graphQLRequest) string {
+		if q.Query != "query { me { canonicalName } }" {
+			t.Errorf("Unexpected query: %q", q.Query)
+		}
+		if !reflect.DeepEqual(q.Variables, map[string]interface{}{"id": float64(1)}) {
+			t.Errorf("Unexpected variables: %v", q.Variables)
+		}
+		return `{"data": {"me": {"canonicalName": "~user"}}}`
+	})
+
+	var v struct {
+		Me struct {
+			CanonicalName string `json:"canonicalName"`
+		} `json:"me"`
+	}
+	err := g.Query(context.Background(), "query { me { canonicalName } }", map[string]interface{}{"id": 1}, &v)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v.Me.CanonicalName != "~user" {
+		t.Fatalf("Unexpected data: want=~user, got=%q", v.Me.CanonicalName)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	g := newGraphQLServer(t, func(graphQLRequest) string {
+		return `{
+  "data": {"me": null},
+  "errors": [{
+    "message": "Access denied",
+    "path": ["me", 0],
+    "extensions": {"field": "name"}
+  }]
+}`
+	})
+
+	err := g.Query(context.Background(), "query { me { id } }", nil, nil)
+	e, ok := err.(sourcehut.Error)
+	if !ok {
+		t.Fatalf("Unexpected error type %T: %v", err, err)
+	}
+	if e.Reason != "Access denied" || e.Field != "name" || e.StatusCode() != 200 {
+		t.Errorf("Unexpected error: %#v", e)
+	}
+	if want := []interface{}{"me", float64(0)}; !reflect.DeepEqual(e.Path, want) {
+		t.Errorf("Unexpected path: want=%v, got=%v", want, e.Path)
+	}
+
+	g = newGraphQLServer(t, func(graphQLRequest) string {
+		return `{"errors": [{"message": "one"}, {"message": "two"}]}`
+	})
+	err = g.Query(context.Background(), "query { me { id } }", nil, nil)
+	if errs, ok := err.(sourcehut.Errors); !ok || len(errs) != 2 || errs.Error() != "one; two" {
+		t.Fatalf("Unexpected errors: %#v", err)
+	}
+}
+
+func TestGraphQLList(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"data": {"me": {"repositories": {"cursor": "c1", "results": [{"id": 1}, {"id": 2}]}}}}`,
+		"c1": `{"data": {"me": {"repositories": {"cursor": null, "results": [{"id": 3}]}}}}`,
+	}
+	g := newGraphQLServer(t, func(q graphQLRequest) string {
+		if q.Variables["owner"] != "~user" {
+			t.Errorf("Variables not sent with page: %v", q.Variables)
+		}
+		cursor, _ := q.Variables["cursor"].(string)
+		return pages[cursor]
+	})
+
+	type repo struct {
+		ID int `json:"id"`
+	}
+	iter := g.List(context.Background(), "query($cursor: Cursor) { … }", map[string]interface{}{"owner": "~user"}, "me.repositories", func() interface{} {
+		return &repo{}
+	})
+	var ids []int
+	for iter.Next() {
+		ids = append(ids, iter.Current().(*repo).ID)
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Unexpected results: want=%v, got=%v", want, ids)
+	}
+}
@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Names of the Sourcehut services, as used by Instance and CallInfo.
+const (
+	ServiceMeta  = "meta"
+	ServiceGit   = "git"
+	ServiceTodo  = "todo"
+	ServiceLists = "lists"
+	ServicePaste = "paste"
+)
+
+// Services lists the services that are probed by Instance.Probe by default.
+var Services = []string{ServiceMeta, ServiceGit, ServiceTodo, ServiceLists, ServicePaste}
+
+// DefaultDomain is the root domain of the public Sourcehut instance.
+const DefaultDomain = "sr.ht"
+
+var errNoClient = errors.New("sourcehut: no client provided for the instance")
+
+// InstanceOption is used to configure an Instance.
+type InstanceOption func(*Instance) error
+
+// ServiceBase returns an option that overrides the API URL of a single service
+// for instances that do not follow the usual naming scheme.
+// If base is empty the option has no effect.
+func ServiceBase(service, base string) InstanceOption {
+	return func(i *Instance) error {
+		if base == "" {
+			return nil
+		}
+		u, err := url.Parse(base)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		i.bases[service] = u.String()
+		return nil
+	}
+}
+
+// Instance describes a Sourcehut deployment, such as the public instance at
+// sr.ht or a self-hosted one, and the client used to access it.
+//
+// Each service is expected to be served from a subdomain of the root domain
+// named after the service, so the git API of the instance at example.org is
+// found at https://git.example.org/api/.
+// The ServiceBase option can be used for services that are hosted elsewhere.
+//
+// The service packages accept an Instance using their Instance option:
+//
+//	inst, err := sourcehut.NewInstance("example.org", srhtClient)
+//	if err != nil {
+//		…
+//	}
+//	gitClient, err := git.NewClient(git.Instance(inst))
+//
+// An Instance is safe for concurrent use.
+type Instance struct {
+	client Client
+	root   *url.URL
+	bases  map[string]string
+}
+
+// NewInstance returns an Instance for the deployment at the provided root
+// domain that makes requests using client.
+//
+// The domain may include a scheme and port (eg. "http://localhost:8080"), if
+// no scheme is provided HTTPS is used.
+// If domain is empty, DefaultDomain is used.
+// The client is required: since the zero Client has no access token or user
+// agent, NewInstance returns an error if client is the zero value.
+func NewInstance(domain string, client Client, opts ...InstanceOption) (*Instance, error) {
+	if domain == "" {
+		domain = DefaultDomain
+	}
+	if !strings.Contains(domain, "://") {
+		domain = "https://" + domain
+	}
+	root, err := url.Parse(domain)
+	if err != nil {
+		return nil, err
+	}
+	if root.Host == "" || strings.Trim(root.Path, "/") != "" {
+		return nil, fmt.Errorf("sourcehut: invalid instance domain %q", domain)
+	}
+
+	if client.httpClient == nil {
+		return nil, errNoClient
+	}
+	i := &Instance{
+		client: client,
+		root:   root,
+		bases:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		if err = opt(i); err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+// Client returns the client used to make requests to the instance.
+func (i *Instance) Client() Client {
+	return i.client
+}
+
+// Domain returns the root domain of the instance.
+func (i *Instance) Domain() string {
+	return i.root.Host
+}
+
+// BaseURL returns the API URL of the provided service (eg.
+// sourcehut.ServiceGit), suitable for use with the service client's Base
+// option.
+// Services that are not known to this package are supported so that clients
+// for new services can use an Instance as well.
+func (i *Instance) BaseURL(service string) string {
+	if base, ok := i.bases[service]; ok {
+		return base
+	}
+	u := url.URL{
+		Scheme: i.root.Scheme,
+		Host:   service + "." + i.root.Host,
+		Path:   "/api/",
+	}
+	return u.String()
+}
+
+// ServiceStatus is the result of probing a single service.
+type ServiceStatus struct {
+	Service string
+	BaseURL string
+
+	// Version is the API version reported by the service.
+	Version string
+
+	// Err is the error that occurred while probing the service, if any.
+	Err error
+}
+
+// Available reports whether the service responded to the probe.
+func (s ServiceStatus) Available() bool {
+	return s.Err == nil
+}
+
+// Probe requests the version of each of the provided services concurrently and
+// reports which ones are available.
+// If no services are provided, Services is used.
+// The results are in the same order as the services.
+func (i *Instance) Probe(ctx context.Context, services ...string) []ServiceStatus {
+	if len(services) == 0 {
+		services = Services
+	}
+
+	statuses := make([]ServiceStatus, len(services))
+	var wg sync.WaitGroup
+	for n, service := range services {
+		statuses[n] = ServiceStatus{
+			Service: service,
+			BaseURL: i.BaseURL(service),
+		}
+		wg.Add(1)
+		go func(s *ServiceStatus) {
+			defer wg.Done()
+			s.Version, s.Err = i.version(ctx, s.Service, s.BaseURL)
+		}(&statuses[n])
+	}
+	wg.Wait()
+	return statuses
+}
+
+func (i *Instance) version(ctx context.Context, service, base string) (string, error) {
+	ctx = WithCallInfo(ctx, CallInfo{Service: service, Endpoint: "version"})
+	req, err := http.NewRequestWithContext(ctx, "GET", base+"version", nil)
+	if err != nil {
+		return "", err
+	}
+	var ver struct {
+		Version string `json:"version"`
+	}
+	_, err = i.client.Do(req, &ver)
+	if err != nil {
+		return "", err
+	}
+	if ver.Version == "" {
+		return "", errors.New("sourcehut: no version in response")
+	}
+	return ver.Version, nil
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+	"git.sr.ht/~wombelix/sourcehut-go/srhttest"
+)
+
+var baseURLTests = [...]struct {
+	domain  string
+	opts    []sourcehut.InstanceOption
+	service string
+	base    string
+	err     bool
+}{
+	0: {service: sourcehut.ServiceGit, base: "https://git.sr.ht/api/"},
+	1: {domain: "example.org", service: sourcehut.ServiceMeta, base: "https://meta.example.org/api/"},
+	2: {domain: "http://localhost:8080/", service: sourcehut.ServiceTodo, base: "http://todo.localhost:8080/api/"},
+	3: {domain: "example.org", service: "builds", base: "https://builds.example.org/api/"},
+	4: {
+		domain:  "example.org",
+		opts:    []sourcehut.InstanceOption{sourcehut.ServiceBase(sourcehut.ServiceLists, "https://lists.example.net/api")},
+		service: sourcehut.ServiceLists,
+		base:    "https://lists.example.net/api/",
+	},
+	5: {
+		domain:  "example.org",
+		opts:    []sourcehut.InstanceOption{sourcehut.ServiceBase(sourcehut.ServiceLists, "")},
+		service: sourcehut.ServiceLists,
+		base:    "https://lists.example.org/api/",
+	},
+	6: {domain: "example.org/git", err: true},
+	7: {domain: "https://", err: true},
+}
+
+func TestInstanceBaseURL(t *testing.T) {
+	for i, tc := range baseURLTests {
+		inst, err := sourcehut.NewInstance(tc.domain, sourcehut.NewClient(), tc.opts...)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%d: expected an error for domain %q", i, tc.domain)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+		if base := inst.BaseURL(tc.service); base != tc.base {
+			t.Errorf("%d: wrong base URL: want=%q, got=%q", i, tc.base, base)
+		}
+	}
+}
+
+func TestInstanceNoClient(t *testing.T) {
+	if _, err := sourcehut.NewInstance("example.org", sourcehut.Client{}); err == nil {
+		t.Errorf("Expected an error for the zero client")
+	}
+}
+
+func TestInstanceProbe(t *testing.T) {
+	srv := srhttest.NewServer()
+	defer srv.Close()
+
+	missing := httptest.NewUnstartedServer(http.NotFoundHandler())
+	missing.Config.ErrorLog = testlog.New(t)
+	missing.Start()
+	defer missing.Close()
+
+	inst, err := sourcehut.NewInstance(missing.URL, srv.Client(),
+		sourcehut.ServiceBase(sourcehut.ServiceMeta, srv.URL(srhttest.Meta)),
+		sourcehut.ServiceBase(sourcehut.ServiceGit, srv.URL(srhttest.Git)),
+		sourcehut.ServiceBase("builds", missing.URL+"/builds/api/"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := inst.Probe(context.Background(), sourcehut.ServiceMeta, sourcehut.ServiceGit, "builds")
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 statuses, got %d", len(statuses))
+	}
+	for _, s := range statuses[:2] {
+		if !s.Available() || s.Version != srhttest.Version {
+			t.Errorf("Expected %s to be available with version %q, got %q and err: %v", s.Service, srhttest.Version, s.Version, s.Err)
+		}
+	}
+	if s := statuses[2]; s.Service != "builds" || s.Available() || !sourcehut.IsNotFound(s.Err) {
+		t.Errorf("Expected builds to be unavailable, got %+v", s)
+	}
+
+	gitClient, err := git.NewClient(git.Instance(inst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ver, err := gitClient.Version(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error using client created from instance: %v", err)
+	}
+	if ver != srhttest.Version {
+		t.Errorf("Wrong version: want=%q, got=%q", srhttest.Version, ver)
+	}
+
+	// The default services are probed when none are provided.
+	if n := len(srv.Instance().Probe(context.Background())); n != len(sourcehut.Services) {
+		t.Errorf("Expected %d statuses, got %d", len(sourcehut.Services), n)
+	}
+}
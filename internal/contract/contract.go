@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package contract checks the data types of the service packages against
+// example API responses.
+//
+// Each service package keeps its examples in testdata/contract.json, a JSON
+// object that maps the name of every data type in the package to an object in
+// the form returned by the API.
+// When the API adds, removes, or renames a field the fixture should be updated
+// from a real response and the test will point out the types that need to
+// change.
+package contract
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+)
+
+// Check decodes each fixture in the file at path into the value returned by
+// the function with the same name in types.
+//
+// It fails the test if a type has no fixture (or a fixture has no type), if a
+// fixture has fields that the type would ignore, or if encoding the decoded
+// value does not result in the same fields as the fixture, which catches
+// misspelled struct tags and fields that are missing from the fixture.
+func Check(t *testing.T, path string, types map[string]func() interface{}) {
+	t.Helper()
+
+	/* #nosec */
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures map[string]json.RawMessage
+	err = json.Unmarshal(data, &fixtures)
+	if err != nil {
+		t.Fatalf("Error decoding fixtures in %s: %v", path, err)
+	}
+	for name := range fixtures {
+		if _, ok := types[name]; !ok {
+			t.Errorf("Fixture %s does not have a type", name)
+		}
+	}
+
+	for name, newV := range types {
+		t.Run(name, func(t *testing.T) {
+			fixture, ok := fixtures[name]
+			if !ok {
+				t.Fatalf("No fixture for %s in %s", name, path)
+			}
+			v := newV()
+			err := json.Unmarshal(fixture, v)
+			if err != nil {
+				t.Fatalf("Error decoding fixture: %v", err)
+			}
+			if unknown := sourcehut.UnknownFields(fixture, v); unknown != nil {
+				t.Errorf("Fixture has fields that are not decoded: %v", unknown)
+			}
+
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				t.Fatalf("Error encoding value: %v", err)
+			}
+			want, got := fieldPaths(t, fixture), fieldPaths(t, encoded)
+			for _, p := range want {
+				if !slices.Contains(got, p) {
+					t.Errorf("Field %s is not encoded with the same name, check the struct tags", p)
+				}
+			}
+			for _, p := range got {
+				if !slices.Contains(want, p) {
+					t.Errorf("Field %s is missing from the fixture", p)
+				}
+			}
+		})
+	}
+}
+
+// fieldPaths returns the sorted paths of all object fields in a JSON document,
+// eg. "owner.name" or "entries[].id".
+func fieldPaths(t *testing.T, data []byte) []string {
+	t.Helper()
+	var doc interface{}
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	var walk func(v interface{}, prefix string)
+	walk = func(v interface{}, prefix string) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, child := range v {
+				p := k
+				if prefix != "" {
+					p = prefix + "." + k
+				}
+				paths = append(paths, p)
+				walk(child, p)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child, prefix+"[]")
+			}
+		}
+	}
+	walk(doc, "")
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package gqlcheck checks the GraphQL documents sent by the service packages
+// against the schema of the service.
+//
+// Each service package that uses the GraphQL API keeps the schema in
+// testdata/schema.graphqls and exposes its documents to its tests.
+// The check catches misspelled fields and arguments, selections that the
+// schema does not allow, and variables that do not match the arguments they
+// are passed to.
+// It only supports the parts of the language that the service packages use:
+// fragments are rejected.
+package gqlcheck
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+)
+
+// Check parses the schema in the file at path and validates each document in
+// docs against it, failing the test if a document is invalid.
+//
+// For each document it returns the fields that are selected and carry a
+// directive in the schema, such as "Query.me @access", so that the test can
+// check that limits on the use of fields are documented.
+func Check(t *testing.T, path string, docs map[string]string) map[string][]string {
+	t.Helper()
+
+	/* #nosec */
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := parseSchema(string(data))
+	if err != nil {
+		t.Fatalf("Error parsing schema in %s: %v", path, err)
+	}
+
+	directives := make(map[string][]string)
+	for name, doc := range docs {
+		t.Run(name, func(t *testing.T) {
+			d, err := s.check(doc)
+			if err != nil {
+				t.Fatalf("Invalid document: %v", err)
+			}
+			directives[name] = d
+		})
+	}
+	return directives
+}
+
+// checker validates a single operation.
+type checker struct {
+	*schema
+	p          *parser
+	vars       map[string]typeRef
+	used       map[string]bool
+	directives []string
+}
+
+func (s *schema) check(doc string) ([]string, error) {
+	toks, err := lex(doc)
+	if err != nil {
+		return nil, err
+	}
+	c := &checker{
+		schema: s,
+		p:      &parser{toks: toks},
+		vars:   make(map[string]typeRef),
+		used:   make(map[string]bool),
+	}
+	root := s.query
+	switch {
+	case c.p.skip("query"):
+	case c.p.skip("mutation"):
+		root = s.mutation
+	case c.p.is("{"):
+	default:
+		return nil, fmt.Errorf("expected operation, got %s", c.p.peek())
+	}
+	if c.p.peek().kind == tokName {
+		c.p.next()
+	}
+	if c.p.skip("(") {
+		if err = c.variables(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = c.p.directives(); err != nil {
+		return nil, err
+	}
+	if _, ok := s.types[root]; !ok {
+		return nil, fmt.Errorf("schema has no type %s", root)
+	}
+	if err = c.selection(root); err != nil {
+		return nil, err
+	}
+	if t := c.p.next(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s after operation, only one operation is supported", t)
+	}
+	for name := range c.vars {
+		if !c.used[name] {
+			return nil, fmt.Errorf("variable $%s is never used", name)
+		}
+	}
+	slices.Sort(c.directives)
+	return slices.Compact(c.directives), nil
+}
+
+func (c *checker) variables() error {
+	for !c.p.skip(")") {
+		if err := c.p.expect("$"); err != nil {
+			return err
+		}
+		name, err := c.p.name()
+		if err != nil {
+			return err
+		}
+		if err = c.p.expect(":"); err != nil {
+			return err
+		}
+		typ, err := c.p.typeRef()
+		if err != nil {
+			return err
+		}
+		if t, ok := c.types[typ.named()]; !ok || (t.kind != "scalar" && t.kind != "enum" && t.kind != "input") {
+			return fmt.Errorf("variable $%s has type %s, which is not an input type", name, typ)
+		}
+		if c.p.skip("=") {
+			if _, err = c.p.value(); err != nil {
+				return err
+			}
+		}
+		c.vars[name] = typ
+	}
+	return nil
+}
+
+// selection checks a selection set on the named type.
+func (c *checker) selection(typeName string) error {
+	parent := c.types[typeName]
+	if err := c.p.expect("{"); err != nil {
+		return err
+	}
+	if c.p.is("}") {
+		return fmt.Errorf("empty selection on %s", typeName)
+	}
+	for !c.p.skip("}") {
+		if c.p.is("...") {
+			return fmt.Errorf("fragments are not supported")
+		}
+		name, err := c.p.name()
+		if err != nil {
+			return err
+		}
+		if c.p.skip(":") {
+			if name, err = c.p.name(); err != nil {
+				return err
+			}
+		}
+		if name == "__typename" {
+			if _, err = c.p.directives(); err != nil {
+				return err
+			}
+			continue
+		}
+		f, ok := parent.fields[name]
+		if !ok {
+			return fmt.Errorf("%s has no field %s", typeName, name)
+		}
+		for _, d := range f.directives {
+			c.directives = append(c.directives, fmt.Sprintf("%s.%s @%s", typeName, name, d))
+		}
+		if err = c.arguments(typeName, name, f); err != nil {
+			return err
+		}
+		if _, err = c.p.directives(); err != nil {
+			return err
+		}
+
+		child := f.typ.named()
+		t, ok := c.types[child]
+		if !ok {
+			return fmt.Errorf("schema has no type %s for %s.%s", child, typeName, name)
+		}
+		leaf := t.kind == "scalar" || t.kind == "enum"
+		switch {
+		case leaf && c.p.is("{"):
+			return fmt.Errorf("%s.%s has type %s and cannot have a selection", typeName, name, f.typ)
+		case !leaf && !c.p.is("{"):
+			return fmt.Errorf("%s.%s has type %s and needs a selection", typeName, name, f.typ)
+		case !leaf:
+			if t.kind == "union" {
+				return fmt.Errorf("%s.%s is a union, which needs fragments", typeName, name)
+			}
+			if err = c.selection(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// arguments checks the arguments passed to a field.
+func (c *checker) arguments(typeName, name string, f *field) error {
+	given := make(map[string]bool)
+	if c.p.skip("(") {
+		for !c.p.skip(")") {
+			arg, err := c.p.name()
+			if err != nil {
+				return err
+			}
+			def, ok := f.args[arg]
+			if !ok {
+				return fmt.Errorf("%s.%s has no argument %s", typeName, name, arg)
+			}
+			if err = c.p.expect(":"); err != nil {
+				return err
+			}
+			bare := c.p.is("$")
+			vars, err := c.p.value()
+			if err != nil {
+				return err
+			}
+			for _, v := range vars {
+				typ, ok := c.vars[v]
+				if !ok {
+					return fmt.Errorf("variable $%s is not defined", v)
+				}
+				c.used[v] = true
+				// Variables nested in lists and input objects are only checked
+				// for being defined.
+				if bare && !def.typ.accepts(typ) {
+					return fmt.Errorf("variable $%s of type %s cannot be passed to %s.%s(%s:) of type %s", v, typ, typeName, name, arg, def.typ)
+				}
+			}
+			given[arg] = true
+		}
+	}
+	for arg, def := range f.args {
+		if def.typ.nonNull && !def.hasDefault && !given[arg] {
+			return fmt.Errorf("%s.%s requires argument %s", typeName, name, arg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package gqlcheck
+
+import (
+	"slices"
+	"testing"
+)
+
+const testSchema = `
+"Marks fields that are not available to API users."
+directive @internal on FIELD_DEFINITION
+directive @access(scope: Scope!, kind: Kind!) on FIELD_DEFINITION | OBJECT
+
+enum Scope { PROFILE @deprecated KEYS }
+enum Kind { RO RW }
+
+scalar Time
+
+interface Entity {
+  id: Int!
+}
+
+"""
+A user.
+"""
+type User implements Entity {
+  id: Int!
+  name: String!
+  created: Time!
+  keys(cursor: String, limit: Int = 10): [Key!]! @access(scope: KEYS, kind: RO)
+}
+
+type Key { id: Int! comment: String }
+
+input KeyInput { key: String! comment: String = "" }
+
+union Result = User | Key
+
+type Query {
+  me: User!
+  user(name: String!): User
+  tokens: [Key!]! @internal
+  result: Result
+}
+
+type Mutation {
+  addKey(input: KeyInput!, tags: [String!]): Key! @access(scope: KEYS, kind: RW)
+  deleteKey(id: Int!): Key
+}
+`
+
+func TestCheck(t *testing.T) {
+	s, err := parseSchema(testSchema)
+	if err != nil {
+		t.Fatalf("Error parsing schema: %v", err)
+	}
+	for i, tc := range [...]struct {
+		doc        string
+		directives []string
+		err        bool
+	}{
+		0: {doc: `{ me { id name } }`},
+		1: {doc: `query { me { id keys { id comment } } }`, directives: []string{"User.keys @access"}},
+		2: {doc: `query($name: String!) { user(name: $name) { name } }`},
+		3: {doc: `query($n: String!) { u: user(name: $n) { __typename n: name } }`},
+		4: {doc: `query { tokens { id } }`, directives: []string{"Query.tokens @internal"}},
+		5: {doc: `mutation($k: String!, $tag: String!) { addKey(input: {key: $k}, tags: [$tag]) { id } }`, directives: []string{"Mutation.addKey @access"}},
+		6: {doc: `mutation($id: Int!) { deleteKey(id: $id) { id } }`},
+
+		7:  {doc: `{ me { id nmae } }`, err: true},
+		8:  {doc: `{ mee { id } }`, err: true},
+		9:  {doc: `{ me }`, err: true},
+		10: {doc: `{ me { id { x } } }`, err: true},
+		11: {doc: `query($name: String!) { user(nam: $name) { id } }`, err: true},
+		12: {doc: `{ user { id } }`, err: true},
+		13: {doc: `query($name: String) { user(name: $name) { id } }`, err: true},
+		14: {doc: `query($id: String!) { me { id } }`, err: true},
+		15: {doc: `{ user(name: $name) { id } }`, err: true},
+		16: {doc: `mutation { me { id } }`, err: true},
+		17: {doc: `{ me { ...F } }`, err: true},
+		18: {doc: `{ result }`, err: true},
+		19: {doc: `{ me { id } } { me { id } }`, err: true},
+		20: {doc: `query($u: User) { me { id } }`, err: true},
+	} {
+		directives, err := s.check(tc.doc)
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("%d: expected an error for %s", i, tc.doc)
+		case !tc.err && err != nil:
+			t.Errorf("%d: unexpected error: %v", i, err)
+		case !slices.Equal(directives, tc.directives):
+			t.Errorf("%d: unexpected directives: want=%v, got=%v", i, tc.directives, directives)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package gqlcheck
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokString
+	tokNumber
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	line int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of document"
+	}
+	return fmt.Sprintf("%q on line %d", t.val, t.line)
+}
+
+// lex splits a GraphQL document into tokens.
+// Commas, whitespace, and comments are ignored as the spec requires, and the
+// values of strings are not unescaped since they are only ever skipped.
+func lex(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			toks = append(toks, token{kind: tokPunct, val: "...", line: line})
+			i += 3
+		case strings.ContainsRune("!$&()/:=@[]{}|", rune(c)):
+			toks = append(toks, token{kind: tokPunct, val: string(c), line: line})
+			i++
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || 'a' <= src[j] && src[j] <= 'z' || 'A' <= src[j] && src[j] <= 'Z' || '0' <= src[j] && src[j] <= '9') {
+				j++
+			}
+			toks = append(toks, token{kind: tokName, val: src[i:j], line: line})
+			i = j
+		case c == '-' || '0' <= c && c <= '9':
+			j := i + 1
+			for j < len(src) && strings.ContainsRune("0123456789.eE+-", rune(src[j])) {
+				j++
+			}
+			toks = append(toks, token{kind: tokNumber, val: src[i:j], line: line})
+			i = j
+		case strings.HasPrefix(src[i:], `"""`):
+			end := strings.Index(src[i+3:], `"""`)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated block string on line %d", line)
+			}
+			s := src[i : i+3+end+3]
+			toks = append(toks, token{kind: tokString, val: s, line: line})
+			line += strings.Count(s, "\n")
+			i += len(s)
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' && src[j] != '\n' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) || src[j] != '"' {
+				return nil, fmt.Errorf("unterminated string on line %d", line)
+			}
+			toks = append(toks, token{kind: tokString, val: src[i : j+1], line: line})
+			i = j + 1
+		default:
+			return nil, fmt.Errorf("unexpected character %q on line %d", c, line)
+		}
+	}
+	return append(toks, token{kind: tokEOF, line: line}), nil
+}
+
+// parser consumes tokens.
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the next token is the punctuator or name s.
+func (p *parser) is(s string) bool {
+	t := p.peek()
+	return (t.kind == tokPunct || t.kind == tokName) && t.val == s
+}
+
+// skip consumes the next token if it is the punctuator or name s.
+func (p *parser) skip(s string) bool {
+	if p.is(s) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if t := p.next(); (t.kind != tokPunct && t.kind != tokName) || t.val != s {
+		return fmt.Errorf("expected %q, got %s", s, t)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokName {
+		return "", fmt.Errorf("expected name, got %s", t)
+	}
+	return t.val, nil
+}
+
+// typeRef parses a type reference such as "[String!]!".
+func (p *parser) typeRef() (typeRef, error) {
+	var ref typeRef
+	if p.skip("[") {
+		elem, err := p.typeRef()
+		if err != nil {
+			return ref, err
+		}
+		if err = p.expect("]"); err != nil {
+			return ref, err
+		}
+		ref.elem = &elem
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return ref, err
+		}
+		ref.name = name
+	}
+	ref.nonNull = p.skip("!")
+	return ref, nil
+}
+
+// value parses a value and returns the names of the variables in it, which may
+// be nested in lists or objects.
+func (p *parser) value() ([]string, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokPunct && t.val == "$":
+		name, err := p.name()
+		return []string{name}, err
+	case t.kind == tokPunct && t.val == "[":
+		var vars []string
+		for !p.skip("]") {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			vars = append(vars, v...)
+		}
+		return vars, nil
+	case t.kind == tokPunct && t.val == "{":
+		var vars []string
+		for !p.skip("}") {
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			vars = append(vars, v...)
+		}
+		return vars, nil
+	case t.kind == tokName || t.kind == tokString || t.kind == tokNumber:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("expected value, got %s", t)
+}
+
+// directives parses any directives and returns their names.
+func (p *parser) directives() ([]string, error) {
+	var names []string
+	for p.skip("@") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if p.skip("(") {
+			for !p.skip(")") {
+				if _, err = p.name(); err != nil {
+					return nil, err
+				}
+				if err = p.expect(":"); err != nil {
+					return nil, err
+				}
+				if _, err = p.value(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// description skips the description of a definition, if there is one.
+func (p *parser) description() {
+	if p.peek().kind == tokString {
+		p.next()
+	}
+}
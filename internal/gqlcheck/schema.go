@@ -0,0 +1,285 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package gqlcheck
+
+import (
+	"fmt"
+)
+
+// typeRef is a reference to a type, such as "[String!]!".
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+func (t typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// named returns the name of the type with any lists and non-null wrappers
+// removed.
+func (t typeRef) named() string {
+	if t.elem != nil {
+		return t.elem.named()
+	}
+	return t.name
+}
+
+// equal reports whether t and u refer to the same type.
+func (t typeRef) equal(u typeRef) bool {
+	if t.nonNull != u.nonNull || t.name != u.name || (t.elem == nil) != (u.elem == nil) {
+		return false
+	}
+	return t.elem == nil || t.elem.equal(*u.elem)
+}
+
+// accepts reports whether a variable of type v can be passed to an argument
+// of type t.
+func (t typeRef) accepts(v typeRef) bool {
+	if t.equal(v) {
+		return true
+	}
+	// A non-null variable can always be passed to a nullable argument.
+	if !t.nonNull && v.nonNull {
+		v.nonNull = false
+		return t.equal(v)
+	}
+	return false
+}
+
+type inputValue struct {
+	typ        typeRef
+	hasDefault bool
+}
+
+type field struct {
+	typ        typeRef
+	args       map[string]inputValue
+	directives []string
+}
+
+type typeDef struct {
+	kind   string
+	fields map[string]*field
+}
+
+// schema is the subset of a schema that is needed to check documents.
+type schema struct {
+	types    map[string]*typeDef
+	query    string
+	mutation string
+}
+
+var builtinScalars = []string{"Int", "Float", "String", "Boolean", "ID"}
+
+// parseSchema parses a schema in the GraphQL schema definition language.
+func parseSchema(src string) (*schema, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	s := &schema{types: make(map[string]*typeDef), query: "Query", mutation: "Mutation"}
+	for _, name := range builtinScalars {
+		s.types[name] = &typeDef{kind: "scalar"}
+	}
+	p := &parser{toks: toks}
+	for p.peek().kind != tokEOF {
+		if err := s.definition(p); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *schema) definition(p *parser) error {
+	p.description()
+	p.skip("extend")
+	kw, err := p.name()
+	if err != nil {
+		return err
+	}
+	switch kw {
+	case "schema":
+		return s.schemaDef(p)
+	case "directive":
+		return skipDirectiveDef(p)
+	case "scalar", "type", "interface", "input", "enum", "union":
+	default:
+		return fmt.Errorf("unexpected definition %q", kw)
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	t := s.types[name]
+	if t == nil {
+		t = &typeDef{kind: kw, fields: make(map[string]*field)}
+		s.types[name] = t
+	}
+	if p.skip("implements") {
+		p.skip("&")
+		for {
+			if _, err = p.name(); err != nil {
+				return err
+			}
+			if !p.skip("&") {
+				break
+			}
+		}
+	}
+	if _, err = p.directives(); err != nil {
+		return err
+	}
+	switch kw {
+	case "union":
+		if p.skip("=") {
+			p.skip("|")
+			for {
+				if _, err = p.name(); err != nil {
+					return err
+				}
+				if !p.skip("|") {
+					break
+				}
+			}
+		}
+		return nil
+	case "scalar":
+		return nil
+	}
+	if !p.skip("{") {
+		return nil
+	}
+	for !p.skip("}") {
+		p.description()
+		fname, err := p.name()
+		if err != nil {
+			return err
+		}
+		if kw == "enum" {
+			if _, err = p.directives(); err != nil {
+				return err
+			}
+			continue
+		}
+		f := &field{}
+		if p.skip("(") {
+			if f.args, err = inputValues(p, ")"); err != nil {
+				return err
+			}
+		}
+		if err = p.expect(":"); err != nil {
+			return err
+		}
+		if f.typ, err = p.typeRef(); err != nil {
+			return err
+		}
+		if kw == "input" && p.skip("=") {
+			if _, err = p.value(); err != nil {
+				return err
+			}
+		}
+		if f.directives, err = p.directives(); err != nil {
+			return err
+		}
+		t.fields[fname] = f
+	}
+	return nil
+}
+
+func (s *schema) schemaDef(p *parser) error {
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.skip("}") {
+		op, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err = p.expect(":"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		switch op {
+		case "query":
+			s.query = name
+		case "mutation":
+			s.mutation = name
+		}
+	}
+	return nil
+}
+
+// inputValues parses argument definitions up to the closing token.
+func inputValues(p *parser, end string) (map[string]inputValue, error) {
+	vals := make(map[string]inputValue)
+	for !p.skip(end) {
+		p.description()
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		v := inputValue{}
+		if v.typ, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if p.skip("=") {
+			v.hasDefault = true
+			if _, err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		vals[name] = v
+	}
+	return vals, nil
+}
+
+func skipDirectiveDef(p *parser) error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.skip("(") {
+		if _, err := inputValues(p, ")"); err != nil {
+			return err
+		}
+	}
+	p.skip("repeatable")
+	if err := p.expect("on"); err != nil {
+		return err
+	}
+	p.skip("|")
+	for {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if !p.skip("|") {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package jsonstream reads large string values out of JSON documents without
+// holding the entire value in memory.
+package jsonstream
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	errNotString    = errors.New("jsonstream: value is not a string")
+	errInvalidChar  = errors.New("jsonstream: invalid character in string")
+	errInvalidEsc   = errors.New("jsonstream: invalid escape sequence in string")
+	errWantObject   = errors.New("jsonstream: expected json object")
+	errFieldMissing = errors.New("jsonstream: field not found")
+)
+
+// StringField returns a reader over the unescaped value of the string field
+// called name in the JSON object read from r.
+// Fields that come before it are skipped and anything after it is never read.
+//
+// Unlike encoding/json, invalid UTF-8 in the value is returned unchanged.
+func StringField(r io.Reader, name string) (io.Reader, error) {
+	d := json.NewDecoder(r)
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, errWantObject
+	}
+	for {
+		tok, err = d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if tok == json.Delim('}') {
+			return nil, fmt.Errorf("%w: %q", errFieldMissing, name)
+		}
+		if tok == name {
+			break
+		}
+		var skip json.RawMessage
+		err = d.Decode(&skip)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The decoder has read the key but not the colon that follows it, so pick up
+	// where it left off.
+	br := bufio.NewReader(io.MultiReader(d.Buffered(), r))
+	for _, want := range []byte{':', '"'} {
+		b, err := skipSpace(br)
+		if err != nil {
+			return nil, err
+		}
+		if b != want {
+			if want == '"' {
+				return nil, errNotString
+			}
+			return nil, fmt.Errorf("jsonstream: unexpected character %q", b)
+		}
+	}
+	return &stringReader{r: br}, nil
+}
+
+func skipSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return b, nil
+		}
+	}
+}
+
+// stringReader unescapes the contents of a JSON string after the opening quote
+// up to the closing quote.
+type stringReader struct {
+	r       *bufio.Reader
+	pending []byte
+	buf     [2 * utf8.UTFMax]byte
+	err     error
+}
+
+func (s *stringReader) Read(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		if len(s.pending) > 0 {
+			c := copy(p[n:], s.pending)
+			s.pending = s.pending[c:]
+			n += c
+			continue
+		}
+		if s.err != nil {
+			break
+		}
+		// Copy runs of characters that don't need unescaping straight from the
+		// buffer.
+		buf, _ := s.r.Peek(min(s.r.Buffered(), len(p)-n))
+		run := 0
+		for run < len(buf) && buf[run] != '"' && buf[run] != '\\' && buf[run] >= 0x20 {
+			run++
+		}
+		if run > 0 {
+			n += copy(p[n:], buf[:run])
+			/* #nosec */
+			_, _ = s.r.Discard(run)
+			continue
+		}
+		b, err := s.r.ReadByte()
+		switch {
+		case err == io.EOF:
+			s.err = io.ErrUnexpectedEOF
+		case err != nil:
+			s.err = err
+		case b == '"':
+			s.err = io.EOF
+		case b == '\\':
+			s.err = s.escape()
+		case b < 0x20:
+			s.err = errInvalidChar
+		default:
+			p[n] = b
+			n++
+		}
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, s.err
+}
+
+// escape decodes the escape sequence following a backslash into s.pending.
+func (s *stringReader) escape() error {
+	b, err := s.r.ReadByte()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	switch b {
+	case '"', '\\', '/':
+		s.buf[0] = b
+	case 'b':
+		s.buf[0] = '\b'
+	case 'f':
+		s.buf[0] = '\f'
+	case 'n':
+		s.buf[0] = '\n'
+	case 'r':
+		s.buf[0] = '\r'
+	case 't':
+		s.buf[0] = '\t'
+	case 'u':
+		return s.unicode()
+	default:
+		return errInvalidEsc
+	}
+	s.pending = s.buf[:1]
+	return nil
+}
+
+// unicode decodes a \uXXXX escape, including a following low surrogate if the
+// first one is a high surrogate.
+// Invalid surrogates are replaced with U+FFFD, like encoding/json does.
+func (s *stringReader) unicode() error {
+	r, err := s.hex()
+	if err != nil {
+		return err
+	}
+	s.pending = s.buf[:0]
+	if utf16.IsSurrogate(r) {
+		next, err := s.r.Peek(2)
+		if err == nil && next[0] == '\\' && next[1] == 'u' {
+			/* #nosec */
+			_, _ = s.r.Discard(2)
+			r2, err := s.hex()
+			if err != nil {
+				return err
+			}
+			if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+				s.pending = utf8.AppendRune(s.pending, dec)
+				return nil
+			}
+			s.pending = utf8.AppendRune(s.pending, utf8.RuneError)
+			r = r2
+			if utf16.IsSurrogate(r) {
+				r = utf8.RuneError
+			}
+		} else {
+			r = utf8.RuneError
+		}
+	}
+	s.pending = utf8.AppendRune(s.pending, r)
+	return nil
+}
+
+func (s *stringReader) hex() (rune, error) {
+	var r rune
+	for i := 0; i < 4; i++ {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		switch {
+		case '0' <= b && b <= '9':
+			b -= '0'
+		case 'a' <= b && b <= 'f':
+			b = b - 'a' + 10
+		case 'A' <= b && b <= 'F':
+			b = b - 'A' + 10
+		default:
+			return 0, errInvalidEsc
+		}
+		r = r<<4 | rune(b)
+	}
+	return r, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package jsonstream_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/jsonstream"
+)
+
+var stringFieldTests = [...]struct {
+	in  string
+	err bool
+}{
+	0:  {in: `{"contents": "hello"}`},
+	1:  {in: `{"sha": "abc", "created": {"nested": ["}", "\""]}, "contents" : "x", "after": 1}`},
+	2:  {in: `{"contents": ""}`},
+	3:  {in: `{"contents": "line\nbreak\ttab \"quoted\" back\\slash \/ \b\f\r"}`},
+	4:  {in: `{"contents": "café ☃ 😀 héllo"}`},
+	5:  {in: `{"contents": "lone \ud83d surrogate"}`},
+	6:  {in: `{"contents": "lone \ude00 low"}`},
+	7:  {in: `{"contents": "\ud83dA"}`},
+	8:  {in: `{"sha": "abc"}`, err: true},
+	9:  {in: `{"contents": 42}`, err: true},
+	10: {in: `{"contents": "unterminated`, err: true},
+	11: {in: `{"contents": "bad \x escape"}`, err: true},
+	12: {in: `{"contents": "bad \u12G4 escape"}`, err: true},
+	13: {in: `["contents"]`, err: true},
+}
+
+func TestStringField(t *testing.T) {
+	for i, tc := range stringFieldTests {
+		r, err := jsonstream.StringField(iotest.OneByteReader(strings.NewReader(tc.in)), "contents")
+		var got []byte
+		if err == nil {
+			got, err = io.ReadAll(r)
+		}
+		if tc.err {
+			if err == nil {
+				t.Errorf("%d: expected an error, got %q", i, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+
+		var want struct {
+			Contents string `json:"contents"`
+		}
+		if err := json.Unmarshal([]byte(tc.in), &want); err != nil {
+			t.Fatalf("%d: bad test data: %v", i, err)
+		}
+		if string(got) != want.Contents {
+			t.Errorf("%d: wrong value: want=%q, got=%q", i, want.Contents, got)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package replaytest sets up the replay transports used by the tests of the
+// service packages.
+//
+// The checked-in cassettes are synthetic, see replay/README.md.
+package replaytest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/replay"
+)
+
+var record = flag.Bool("record", false, "replace the synthetic cassettes in testdata with ones recorded against the live API using $SRHT_TOKEN, see replay/README.md")
+
+// Client returns a client that replays the cassette testdata/<name>.json, or
+// records it against the live API if the tests are run with -record.
+// The cassette is checked, or written, when the test ends.
+func Client(t *testing.T, name string) sourcehut.Client {
+	t.Helper()
+	mode, token := replay.ModeReplay, "token"
+	if *record {
+		mode, token = replay.ModeRecord, os.Getenv("SRHT_TOKEN")
+	}
+	rec, err := replay.New(filepath.Join("testdata", name+".json"), mode, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := rec.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return sourcehut.NewClient(
+		sourcehut.Token(token),
+		sourcehut.UserAgent("sourcehut-go tests"),
+		sourcehut.RoundTripper(rec),
+	)
+}
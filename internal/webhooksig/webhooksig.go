@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package webhooksig signs and verifies Sourcehut webhook deliveries.
+//
+// Each delivery is signed with the Ed25519 key of the Sourcehut instance.
+// The signature covers the payload followed by a nonce, and both the base64
+// encoded signature and the nonce are sent in headers alongside the payload.
+//
+// It is shared by package webhook, meta.VerifyWebhook, and package
+// webhooktest.
+package webhooksig
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// Headers set on webhook deliveries.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Payload-Signature"
+	HeaderNonce     = "X-Payload-Nonce"
+)
+
+// Verify reports whether signature is a valid signature of the payload and
+// nonce by the private key matching key.
+func Verify(key ed25519.PublicKey, payload []byte, signature, nonce string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(key, message(payload, nonce), sig)
+}
+
+// Sign returns the base64 encoded signature of the payload and nonce.
+func Sign(key ed25519.PrivateKey, payload []byte, nonce string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, message(payload, nonce)))
+}
+
+// Nonce returns a new random nonce.
+func Nonce() string {
+	var b [16]byte
+	/* #nosec */
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func message(payload []byte, nonce string) []byte {
+	msg := make([]byte, 0, len(payload)+len(nonce))
+	return append(append(msg, payload...), nonce...)
+}
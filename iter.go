@@ -5,14 +5,18 @@
 package sourcehut
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"iter"
 	"net/http"
-	"strings"
 )
 
 var (
-	errWantArray = errors.New("expected json array in response")
+	errWantArray  = errors.New("expected json array in response")
+	errWantObject = errors.New("expected json object in response")
 )
 
 // Response is a Sourcehut API response.
@@ -29,22 +33,384 @@ type Response struct {
 	Total          int64           `json:"total"`
 }
 
-// Iter provides a convenient API for iterating over the elements returned from
-// paginated list API calls.
+// Iter provides a convenient API for iterating over the elements of type T
+// returned from paginated list API calls.
 // Successive calls to the Next method step through each item in the list,
 // fetching pages as needed.
-type Iter struct {
+// Alternatively, the All method can be used to range over the items.
+// Since the list methods of the service clients return an error along with the
+// iterator, All is called on the returned iterator:
+//
+//	repos, err := gitClient.Repos(ctx, "")
+//	if err != nil {
+//		return err
+//	}
+//	for repo, err := range repos.All() {
+//		…
+//	}
+//
+// By default each page is fetched when the caller reaches the end of the
+// previous one; Prefetch can be used to fetch pages in the background instead,
+// and Stream to decode items while the page is still being received.
+//
+// Long running jobs can checkpoint their progress by saving the value of
+// Cursor and later continue from the same page by passing it to Resume.
+type Iter[T any] struct {
+	resp   *Response
+	v      T
+	err    error
+	d      *json.Decoder
+	into   func() T
+	ctx    context.Context
+	page   pageFunc
+	open   openFunc
+	n      int
+	done   bool
+	start  string
+	cursor string
+
+	prefetch int
+	pages    chan pageResult
+	cancel   context.CancelFunc
+
+	stream bool
+	body   io.ReadCloser
+
+	fields fieldCheck
+}
+
+type pageResult struct {
 	resp *Response
-	v    interface{}
 	err  error
-	d    *json.Decoder
-	into func() interface{}
-	req  *http.Request
-	c    Client
+}
+
+// List returns an iterator that can transparently make API requests to a
+// paginated endpoint and decodes each item into a new value of type T.
+//
+// No HTTP request will be issued until iteration is started.
+// The context attached to req is used for every page that is fetched and once
+// it is canceled no further pages will be requested.
+func List[T any](c Client, req *http.Request) *Iter[T] {
+	open := restOpen(c, req)
+	return &Iter[T]{ctx: req.Context(), page: restPage(open), open: open, fields: c.fields}
+}
+
+// pageFunc fetches the page of results starting at the provided cursor.
+// The cursor is empty for the first page.
+type pageFunc func(ctx context.Context, cursor string) (*Response, error)
+
+// openFunc sends the request for the page of results starting at the provided
+// cursor and returns the response without reading its body.
+type openFunc func(ctx context.Context, cursor string) (*http.Response, error)
+
+// restOpen returns an openFunc that requests pages of a REST endpoint using the
+// "start" query parameter.
+// Each page is requested using a fresh copy of req, which itself is never
+// modified.
+func restOpen(c Client, req *http.Request) openFunc {
+	return func(ctx context.Context, cursor string) (*http.Response, error) {
+		r := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		if cursor != "" {
+			q := r.URL.Query()
+			q.Set("start", cursor)
+			r.URL.RawQuery = q.Encode()
+		}
+		return c.do(r)
+	}
+}
+
+// restPage returns a pageFunc that reads and decodes whole pages requested
+// using open.
+func restPage(open openFunc) pageFunc {
+	return func(ctx context.Context, cursor string) (*Response, error) {
+		resp, err := open(ctx, cursor)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		page := &Response{Response: resp}
+		err = json.NewDecoder(resp.Body).Decode(page)
+		if err != nil {
+			return nil, err
+		}
+		return page, nil
+	}
+}
+
+// Prefetch enables fetching up to n pages ahead of the page that is being
+// iterated over in a background goroutine, so that the latency of fetching a
+// page overlaps with processing the previous one.
+// It must be called before iteration starts and returns i for convenience.
+// If n is less than 1, prefetching is disabled.
+//
+// Pages are fetched one at a time since each page links to the next one.
+// At most n pages beyond the one being iterated over are held in memory at
+// once, counting a page that is still being fetched, so the iterator holds up
+// to n+1 pages in total.
+// Prefetching stops when the end of the list is reached, an error occurs, or
+// the iterator's context is canceled.
+// Callers that stop iterating early must call Close or cancel the context to
+// release the goroutine; All does this automatically.
+func (i *Iter[T]) Prefetch(n int) *Iter[T] {
+	i.prefetch = max(n, 0)
+	return i
+}
+
+// Stream makes the iterator decode items directly from the body of each
+// response as they are requested, instead of reading the whole page into
+// memory before decoding the first item.
+// It must be called before iteration starts and returns i for convenience.
+//
+// While streaming, the response body of the current page stays open until
+// every item on it has been visited, so callers that stop iterating early must
+// call Close to release the connection; All does this automatically.
+// Pagination information that the server sends after the results only becomes
+// available once the page has been read.
+// Streaming is not supported by GraphQL iterators or when prefetching, in
+// which case Stream has no effect.
+func (i *Iter[T]) Stream() *Iter[T] {
+	i.stream = true
+	return i
+}
+
+func (i *Iter[T]) streaming() bool {
+	return i.stream && i.open != nil && i.prefetch == 0
+}
+
+// Close stops the iterator: any background prefetching is canceled, the
+// response body of a streamed page is closed, and subsequent calls to Next
+// return false.
+// It is safe to call Close more than once and on iterators that do not
+// prefetch or stream.
+func (i *Iter[T]) Close() {
+	i.done = true
+	if i.cancel != nil {
+		i.cancel()
+	}
+	i.closeBody()
+}
+
+// Total returns the total number of items in the list as reported by the most
+// recently fetched page.
+// It returns 0 if no page has been fetched yet or if the endpoint does not
+// report a total, such as GraphQL endpoints.
+func (i *Iter[T]) Total() int64 {
+	if i.resp == nil {
+		return 0
+	}
+	return i.resp.Total
+}
+
+// ResultsPerPage returns the number of results per page as reported by the most
+// recently fetched page, or 0 if it is not known.
+func (i *Iter[T]) ResultsPerPage() int64 {
+	if i.resp == nil {
+		return 0
+	}
+	return i.resp.ResultsPerPage
+}
+
+// Cursor returns the cursor of the page that contains the current item.
+// It is empty for the first page of a list.
+//
+// Saving the cursor and passing it to Resume later returns an iterator that
+// starts at the beginning of the same page, so items that were already visited
+// on that page will be visited again but no items are skipped.
+func (i *Iter[T]) Cursor() string {
+	return i.cursor
+}
+
+// NextCursor returns the cursor of the page after the one that contains the
+// current item, or an empty string if no page has been fetched yet or the
+// current page is the last one.
+//
+// Once every item on the current page has been processed NextCursor can be
+// saved instead of Cursor to avoid visiting them again on Resume.
+func (i *Iter[T]) NextCursor() string {
+	if i.resp == nil {
+		return ""
+	}
+	return i.resp.Next
+}
+
+// Resume makes the iterator start at the page identified by cursor, which must
+// have been returned by the Cursor or NextCursor method of an iterator over the
+// same list.
+// It must be called before iteration starts and returns i for convenience.
+// An empty cursor starts at the first page.
+//
+// Cursors are opaque values provided by the server and may become invalid if
+// the list changes, in which case the server may return an error or a page
+// that does not line up with the previous iteration.
+func (i *Iter[T]) Resume(cursor string) *Iter[T] {
+	i.start = cursor
+	return i
+}
+
+// fetch returns the page starting at cursor.
+func (i *Iter[T]) fetch(cursor string) (*Response, error) {
+	if i.prefetch == 0 {
+		i.n++
+		return i.page(withPage(i.ctx, i.n), cursor)
+	}
+
+	if i.pages == nil {
+		i.startPrefetch()
+	}
+	select {
+	case p, ok := <-i.pages:
+		if !ok {
+			// The goroutine only stops early if the context was canceled.
+			return nil, i.ctx.Err()
+		}
+		return p.resp, p.err
+	case <-i.ctx.Done():
+		return nil, i.ctx.Err()
+	}
+}
+
+// startPrefetch starts fetching pages in the background.
+func (i *Iter[T]) startPrefetch() {
+	ctx, cancel := context.WithCancel(i.ctx)
+	// The goroutine holds one page while it fetches it or waits to hand it
+	// over, so n-1 more can be buffered without going past n pages ahead.
+	pages := make(chan pageResult, i.prefetch-1)
+	i.cancel = cancel
+	i.pages = pages
+
+	cursor := i.start
+	go func() {
+		// Release the context once the last page has been fetched, even if the
+		// caller never calls Close.
+		defer cancel()
+		defer close(pages)
+		for n := 1; ctx.Err() == nil; n++ {
+			resp, err := i.page(withPage(ctx, n), cursor)
+			select {
+			case pages <- pageResult{resp: resp, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil || resp.Next == "" {
+				return
+			}
+			cursor = resp.Next
+		}
+	}()
+}
+
+// openStream requests the page starting at cursor and reads the response up to
+// the first item in the results.
+func (i *Iter[T]) openStream(cursor string) error {
+	i.n++
+	resp, err := i.open(withPage(i.ctx, i.n), cursor)
+	if err != nil {
+		i.resp = nil
+		return err
+	}
+	i.body = resp.Body
+	i.resp = &Response{Response: resp}
+	i.d = json.NewDecoder(resp.Body)
+	tok, err := i.d.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return errWantObject
+	}
+	found, err := i.readFields(true)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errWantArray
+	}
+	return nil
+}
+
+// finishStream reads the rest of a streamed page after the last item and
+// closes the response body.
+func (i *Iter[T]) finishStream() error {
+	defer i.closeBody()
+	// Skip the end of the results array.
+	_, err := i.d.Token()
+	if err != nil {
+		return err
+	}
+	_, err = i.readFields(false)
+	return err
+}
+
+// readFields decodes the fields of a streamed page into i.resp until the end
+// of the object or, if untilResults is set, until the start of the results
+// array, in which case it reports whether the results were found.
+func (i *Iter[T]) readFields(untilResults bool) (bool, error) {
+	for {
+		tok, err := i.d.Token()
+		if err != nil {
+			return false, err
+		}
+		if tok == json.Delim('}') {
+			return false, nil
+		}
+		var v interface{}
+		switch tok {
+		case "next":
+			v = &i.resp.Next
+		case "total":
+			v = &i.resp.Total
+		case "results_per_page":
+			v = &i.resp.ResultsPerPage
+		case "results":
+			if untilResults {
+				return true, startArray(i.d)
+			}
+			v = &i.resp.Results
+		default:
+			v = new(json.RawMessage)
+		}
+		err = i.d.Decode(v)
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+func (i *Iter[T]) closeBody() {
+	if i.body == nil {
+		return
+	}
+	// Drain the body so that the connection can be reused.
+	/* #nosec */
+	_, _ = io.Copy(io.Discard, io.LimitReader(i.body, 4096))
+	/* #nosec */
+	i.body.Close()
+	i.body = nil
+}
+
+// startArray advances d past the start of a JSON array so that the array can be
+// treated as a stream.
+func startArray(d *json.Decoder) error {
+	tok, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return errWantArray
+	}
+	return nil
 }
 
 // Current returns the most recent item visited by the iterator.
-func (i *Iter) Current() interface{} {
+func (i *Iter[T]) Current() T {
 	return i.v
 }
 
@@ -52,68 +418,116 @@ func (i *Iter) Current() interface{} {
 // It will only return a non-nil value if the previous call to Next returned
 // false (but Next returning false does not guarantee that Err will return a
 // non-nil value).
-func (i *Iter) Err() error {
+func (i *Iter[T]) Err() error {
 	return i.err
 }
 
 // Next advances the iterator to the next item in the list and makes it
 // available through the Current method.
 // When the end of the list is reached it returns False.
-func (i *Iter) Next() bool {
-	if i.err != nil {
+func (i *Iter[T]) Next() bool {
+	if i.err != nil || i.done {
 		return false
 	}
 
 	if i.into == nil {
-		i.v = make(map[string]interface{})
+		var zero T
+		i.v = zero
 	} else {
 		i.v = i.into()
 	}
 
 	if i.d == nil || !i.d.More() {
+		if i.body != nil {
+			// Read the rest of the streamed page to find the link to the next one.
+			i.err = i.finishStream()
+			if i.err != nil {
+				return false
+			}
+		}
+
 		// We're out of JSON to decode, fetch the next page if there is one…
+		cursor := i.start
 		if i.resp != nil {
 			if i.resp.Next == "" {
 				return false
 			}
-
-			// TODO: clone req
-			q := i.req.URL.Query()
-			q.Set("start", i.resp.Next)
-			i.req.URL.RawQuery = q.Encode()
+			cursor = i.resp.Next
 		}
 
-		resp, err := i.c.do(i.req)
-		if err != nil {
+		// Don't start fetching another page if the caller is no longer interested
+		// in the results.
+		if err := i.ctx.Err(); err != nil {
 			i.err = err
 			return false
 		}
-		i.resp = &Response{Response: resp}
-		defer i.resp.Body.Close()
 
-		i.err = json.NewDecoder(i.resp.Body).Decode(i.resp)
-		if i.err != nil {
-			return false
-		}
-		i.d = json.NewDecoder(strings.NewReader(string(i.resp.Results)))
-		// Advance past the first token so that we can treat the array as a stream.
-		tok, err := i.d.Token()
-		if err != nil {
-			i.err = err
-			return false
+		if i.streaming() {
+			i.err = i.openStream(cursor)
+		} else {
+			i.resp, i.err = i.fetch(cursor)
+			if i.err == nil {
+				i.d = json.NewDecoder(bytes.NewReader(i.resp.Results))
+				i.err = startArray(i.d)
+			}
 		}
-		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
-			i.err = errWantArray
+		if i.err != nil {
+			i.closeBody()
 			return false
 		}
+		i.cursor = cursor
 	}
 
 	// If we just fetched the list, but there's nothing in it ('[]'), don't try to
 	// decode an element.
 	if !i.d.More() {
+		if i.body != nil {
+			i.err = i.finishStream()
+		}
+		return false
+	}
+
+	i.err = i.decode()
+	if i.err != nil {
+		i.closeBody()
 		return false
 	}
+	return true
+}
 
-	i.err = i.d.Decode(&i.v)
-	return i.err == nil
+// decode decodes the next item in the results into i.v.
+func (i *Iter[T]) decode() error {
+	if !i.fields.enabled() {
+		return i.d.Decode(&i.v)
+	}
+	var raw json.RawMessage
+	err := i.d.Decode(&raw)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(raw, &i.v)
+	if err != nil {
+		return err
+	}
+	return i.fields.check(raw, &i.v)
+}
+
+// All returns an iterator over the remaining items in the list for use with
+// range-over-func.
+// If an error is encountered it is yielded along with the zero value of T and
+// iteration stops.
+// The iterator is closed when the loop ends.
+func (i *Iter[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer i.Close()
+		for i.Next() {
+			if !yield(i.Current(), nil) {
+				return
+			}
+		}
+		if err := i.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
 }
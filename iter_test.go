@@ -5,12 +5,19 @@
 package sourcehut_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"git.sr.ht/~wombelix/sourcehut-go"
 	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
@@ -25,7 +32,7 @@ type iterTest struct {
 }
 
 var iterTests = [...]iterTest{
-	0: {code: 404, err: io.EOF},
+	0: {code: 404, err: sourcehut.ErrNotFound},
 	1: {
 		code: 400,
 		body: []string{`{"errors": [{"field": "f", "reason": "r"}]}`},
@@ -78,7 +85,20 @@ var iterTests = [...]iterTest{
 }
 
 func TestIter(t *testing.T) {
+	testIter(t, false)
+}
+
+func TestIterStream(t *testing.T) {
+	testIter(t, true)
+}
+
+func testIter(t *testing.T, stream bool) {
 	for i, tc := range iterTests {
+		if stream && i == 4 {
+			// A streamed page is known to be missing its results as soon as the
+			// end of the object is reached.
+			tc.err = sourcehut.ErrWantArray
+		}
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			var served int
 			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -104,11 +124,119 @@ func TestIter(t *testing.T) {
 			srhtClient := sourcehut.NewBaseClient(client)
 			defer server.Close()
 
-			doIterTest(t, server.URL, srhtClient, tc)
+			doIterTest(t, server.URL, srhtClient, tc, stream)
 		})
 	}
 }
 
+func TestIterContext(t *testing.T) {
+	var served int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		served++
+		_, err := w.Write([]byte(`{"next": "next", "results": [{}]}`))
+		if err != nil {
+			t.Fatalf("Error writing response body (this should never happen): %q", err)
+		}
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+	client := sourcehut.NewBaseClient(server.Client())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter := client.List(req, nil)
+	if !iter.Next() {
+		t.Fatalf("Expected first item to be decoded, got err: %v", iter.Err())
+	}
+	cancel()
+	if iter.Next() {
+		t.Fatalf("Next unexpectedly returned true after context was canceled")
+	}
+	if err := iter.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error: want=%v, got=%v", context.Canceled, err)
+	}
+	if served != 1 {
+		t.Fatalf("Unexpected number of pages fetched: want=1, got=%d", served)
+	}
+}
+
+func TestIterAll(t *testing.T) {
+	pages := []string{
+		`{"next": "2", "results": [{"id": 1}, {"id": 2}]}`,
+		`{"results": [{"id": 3}]}`,
+	}
+	var served int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if served >= len(pages) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": [{"reason": "not found"}]}`))
+			return
+		}
+		served++
+		_, err := w.Write([]byte(pages[served-1]))
+		if err != nil {
+			t.Fatalf("Error writing response body (this should never happen): %q", err)
+		}
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+	client := sourcehut.NewBaseClient(server.Client())
+
+	type item struct {
+		ID int `json:"id"`
+	}
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for v, err := range sourcehut.List[*item](client, req).All() {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ids = append(ids, v.ID)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Unexpected items: want=%v, got=%v", want, ids)
+	}
+
+	// Breaking out of the loop early must not fetch any more pages.
+	served = 0
+	req, err = http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range sourcehut.List[*item](client, req).All() {
+		break
+	}
+	if served != 1 {
+		t.Fatalf("Unexpected number of pages fetched: want=1, got=%d", served)
+	}
+
+	// Errors are yielded as the final value.
+	served = len(pages)
+	req, err = http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs int
+	for v, err := range sourcehut.List[*item](client, req).All() {
+		if err == nil || v != nil {
+			t.Fatalf("Expected only an error, got value=%v, err=%v", v, err)
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Fatalf("Unexpected number of errors: want=1, got=%d", errs)
+	}
+}
+
 func errEq(statusCode int, e1, e2 sourcehut.Error) bool {
 	if e1.Field != e2.Field {
 		return false
@@ -119,12 +247,15 @@ func errEq(statusCode int, e1, e2 sourcehut.Error) bool {
 	return e1.StatusCode() == statusCode
 }
 
-func doIterTest(t *testing.T, u string, client sourcehut.Client, tc iterTest) {
+func doIterTest(t *testing.T, u string, client sourcehut.Client, tc iterTest, stream bool) {
 	req, err := http.NewRequest("GET", u, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	iter := client.List(req, tc.d)
+	if stream {
+		iter.Stream()
+	}
 	var i int
 	for iter.Next() {
 		v := iter.Current()
@@ -150,8 +281,363 @@ func doIterTest(t *testing.T, u string, client sourcehut.Client, tc iterTest) {
 				t.Fatalf("Unexpected err: want=%#v, got=%#v", tcErr[i], e[i])
 			}
 		}
+	default:
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("Unexpected err: want=%#v, got=%#v", tc.err, err)
+		}
 	}
 	if err != nil && iter.Next() {
 		t.Fatalf("Next unexpectedly returned true after error")
 	}
 }
+
+func TestIterPrefetch(t *testing.T) {
+	for _, n := range []int{1, 3} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			testIterPrefetch(t, n)
+		})
+	}
+}
+
+func testIterPrefetch(t *testing.T, n int) {
+	const (
+		total = 10
+		size  = 2
+	)
+	requested := make(chan int, total)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start, _ := strconv.Atoi(req.URL.Query().Get("start"))
+		requested <- start / size
+		next := "null"
+		if start+size < total {
+			next = strconv.Quote(strconv.Itoa(start + size))
+		}
+		_, err := fmt.Fprintf(w, `{"next": %s, "results": [%d, %d], "results_per_page": %d, "total": %d}`,
+			next, start, start+1, size, total)
+		if err != nil {
+			t.Errorf("Error writing response body (this should never happen): %q", err)
+		}
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+	client := sourcehut.NewBaseClient(server.Client())
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter := sourcehut.List[int](client, req).Prefetch(n)
+	if iter.Total() != 0 {
+		t.Errorf("Expected no total before the first page, got %d", iter.Total())
+	}
+	if !iter.Next() {
+		t.Fatalf("Expected first item, got err: %v", iter.Err())
+	}
+	if iter.Total() != total || iter.ResultsPerPage() != size {
+		t.Errorf("Unexpected progress: total=%d, per page=%d", iter.Total(), iter.ResultsPerPage())
+	}
+
+	// While the caller is still on the first page, exactly n following pages
+	// are fetched in the background.
+	timeout := time.After(5 * time.Second)
+	for want := 0; want <= n; want++ {
+		select {
+		case page := <-requested:
+			if page != want {
+				t.Fatalf("Unexpected page requested: want=%d, got=%d", want, page)
+			}
+		case <-timeout:
+			t.Fatalf("Page %d was not prefetched", want)
+		}
+	}
+	select {
+	case page := <-requested:
+		t.Fatalf("Page %d was fetched beyond the prefetch limit", page)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	got := []int{iter.Current()}
+	for iter.Next() {
+		got = append(got, iter.Current())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("Unexpected items: %v", got)
+		}
+	}
+	if len(got) != total {
+		t.Fatalf("Expected %d items, got %d", total, len(got))
+	}
+}
+
+func TestIterPrefetchReleasesContext(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next := `"1"`
+		if req.URL.Query().Get("start") == "1" {
+			next = "null"
+		}
+		_, err := fmt.Fprintf(w, `{"next": %s, "results": [{}]}`, next)
+		if err != nil {
+			t.Errorf("Error writing response body (this should never happen): %q", err)
+		}
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+
+	var (
+		mu   sync.Mutex
+		ctxs []context.Context
+	)
+	client := sourcehut.NewBaseClient(&http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			ctxs = append(ctxs, req.Context())
+			mu.Unlock()
+			return server.Client().Transport.RoundTrip(req)
+		}),
+	})
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter := client.List(req, nil).Prefetch(2)
+	n := 0
+	for iter.Next() {
+		n++
+	}
+	if err := iter.Err(); err != nil || n != 2 {
+		t.Fatalf("Expected 2 items, got %d and err: %v", n, err)
+	}
+
+	// The iterator is not closed, but the context used for prefetching must
+	// still be released once the last page has been fetched.
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ctxs) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(ctxs))
+	}
+	select {
+	case <-ctxs[len(ctxs)-1].Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Prefetch context was not released after the last page")
+	}
+}
+
+func TestIterPrefetchCancel(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, err := w.Write([]byte(`{"next": "next", "results": [{}]}`))
+		if err != nil {
+			t.Errorf("Error writing response body (this should never happen): %q", err)
+		}
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+	client := sourcehut.NewBaseClient(server.Client())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter := client.List(req, nil).Prefetch(4)
+	if !iter.Next() {
+		t.Fatalf("Expected first item to be decoded, got err: %v", iter.Err())
+	}
+	cancel()
+	if iter.Next() {
+		t.Fatalf("Next unexpectedly returned true after context was canceled")
+	}
+	if err := iter.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error: want=%v, got=%v", context.Canceled, err)
+	}
+
+	// Closing an iterator stops it without an error.
+	req, err = http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter = client.List(req, nil).Prefetch(4)
+	if !iter.Next() {
+		t.Fatalf("Expected first item to be decoded, got err: %v", iter.Err())
+	}
+	iter.Close()
+	if iter.Next() || iter.Err() != nil {
+		t.Fatalf("Expected closed iterator to stop without error, got: %v", iter.Err())
+	}
+}
+
+func TestIterResume(t *testing.T) {
+	server := pagedServer(t, 7, 3)
+	client := sourcehut.NewClient(
+		sourcehut.Token("token"),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+	)
+	newIter := func() *sourcehut.Iter[int] {
+		req, err := http.NewRequest("GET", server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sourcehut.List[int](client, req)
+	}
+
+	iter := newIter()
+	if iter.Cursor() != "" || iter.NextCursor() != "" {
+		t.Errorf("Expected empty cursors before iteration, got %q and %q", iter.Cursor(), iter.NextCursor())
+	}
+	// Stop part way through the second page.
+	for n := 0; n < 4; n++ {
+		if !iter.Next() {
+			t.Fatalf("Expected item %d, got err: %v", n, iter.Err())
+		}
+	}
+	cursor, next := iter.Cursor(), iter.NextCursor()
+	if cursor != "3" || next != "6" {
+		t.Fatalf("Unexpected cursors: want=%q and %q, got=%q and %q", "3", "6", cursor, next)
+	}
+	if iter.Total() != 7 || iter.ResultsPerPage() != 3 {
+		t.Errorf("Unexpected progress: total=%d, per page=%d", iter.Total(), iter.ResultsPerPage())
+	}
+
+	for _, tc := range []struct {
+		cursor   string
+		prefetch int
+		want     []int
+	}{
+		{cursor: cursor, want: []int{3, 4, 5, 6}},
+		{cursor: cursor, prefetch: 2, want: []int{3, 4, 5, 6}},
+		{cursor: next, want: []int{6}},
+		{cursor: "", want: []int{0, 1, 2, 3, 4, 5, 6}},
+	} {
+		var got []int
+		for v, err := range newIter().Prefetch(tc.prefetch).Resume(tc.cursor).All() {
+			if err != nil {
+				t.Fatalf("Unexpected error resuming from %q: %v", tc.cursor, err)
+			}
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Unexpected items resuming from %q: want=%v, got=%v", tc.cursor, tc.want, got)
+		}
+	}
+}
+
+func TestIterStreamPartial(t *testing.T) {
+	proceed := make(chan struct{})
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("start") == "" {
+			fmt.Fprint(w, `{"results": [1, `)
+			w.(http.Flusher).Flush()
+			// Only send the rest of the page once the first item was decoded.
+			<-proceed
+			fmt.Fprint(w, `2], "next": "2", "total": 3, "results_per_page": 2}`)
+			return
+		}
+		fmt.Fprint(w, `{"total": 3, "results_per_page": 2, "results": [3]}`)
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+	client := sourcehut.NewBaseClient(server.Client())
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter := sourcehut.List[int](client, req).Stream()
+	if !iter.Next() || iter.Current() != 1 {
+		t.Fatalf("Expected first item before the page was complete, got %d and err: %v", iter.Current(), iter.Err())
+	}
+	if iter.Total() != 0 {
+		t.Errorf("Expected total to be unknown until the page is read, got %d", iter.Total())
+	}
+	close(proceed)
+
+	got := []int{iter.Current()}
+	for iter.Next() {
+		got = append(got, iter.Current())
+		if iter.Current() == 3 && (iter.Cursor() != "2" || iter.Total() != 3 || iter.ResultsPerPage() != 2) {
+			t.Errorf("Unexpected page info: cursor=%q, total=%d, per page=%d", iter.Cursor(), iter.Total(), iter.ResultsPerPage())
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unexpected items: want=%v, got=%v", want, got)
+	}
+}
+
+// benchmarkPage is a page of 500 items that are similar in size to a post with
+// a short email envelope.
+var benchmarkPage = func() []byte {
+	type item struct {
+		ID       int64  `json:"id"`
+		Subject  string `json:"subject"`
+		Envelope string `json:"envelope"`
+	}
+	page := struct {
+		Results []item `json:"results"`
+		Total   int    `json:"total"`
+	}{Total: 500}
+	for i := 0; i < 500; i++ {
+		page.Results = append(page.Results, item{
+			ID:       int64(i),
+			Subject:  "[PATCH] Fix the thing " + strconv.Itoa(i),
+			Envelope: strings.Repeat("Received: from example.org by example.org\r\n", 20),
+		})
+	}
+	b, err := json.Marshal(page)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}()
+
+func benchmarkIter(b *testing.B, stream bool) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		/* #nosec */
+		_, _ = w.Write(benchmarkPage)
+	}))
+	defer server.Close()
+	client := sourcehut.NewBaseClient(server.Client())
+	type item struct {
+		ID       int64  `json:"id"`
+		Subject  string `json:"subject"`
+		Envelope string `json:"envelope"`
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkPage)))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		req, err := http.NewRequest("GET", server.URL, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		iter := sourcehut.List[item](client, req)
+		if stream {
+			iter.Stream()
+		}
+		for iter.Next() {
+		}
+		if err := iter.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIter(b *testing.B) {
+	benchmarkIter(b, false)
+}
+
+func BenchmarkIterStream(b *testing.B) {
+	benchmarkIter(b, true)
+}
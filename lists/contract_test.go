@@ -0,0 +1,21 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package lists_test
+
+import (
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/contract"
+	"git.sr.ht/~wombelix/sourcehut-go/lists"
+)
+
+func TestContract(t *testing.T) {
+	contract.Check(t, "testdata/contract.json", map[string]func() interface{}{
+		"ShortList": func() interface{} { return &lists.ShortList{} },
+		"List":      func() interface{} { return &lists.List{} },
+		"ShortPost": func() interface{} { return &lists.ShortPost{} },
+		"Post":      func() interface{} { return &lists.Post{} },
+	})
+}
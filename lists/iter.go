@@ -10,20 +10,20 @@ import (
 
 // ListIter is used for iterating over a collection of mailing lists.
 type ListIter struct {
-	*sourcehut.Iter
+	*sourcehut.Iter[*List]
 }
 
 // List returns the mailing list which the iterator is currently pointing to.
 func (i ListIter) List() *List {
-	return i.Current().(*List)
+	return i.Current()
 }
 
 // PostIter is used for iterating over a collection of mailing list posts.
 type PostIter struct {
-	*sourcehut.Iter
+	*sourcehut.Iter[*Post]
 }
 
 // Post returns the post which the iterator is currently pointing to.
 func (i PostIter) Post() *Post {
-	return i.Current().(*Post)
+	return i.Current()
 }
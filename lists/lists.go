@@ -6,6 +6,7 @@
 package lists
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/url"
@@ -19,6 +20,19 @@ import (
 // It is exported for convenience.
 const BaseURL = "https://lists.sr.ht/api/"
 
+// endpoints identifies the calls made by the client and lists the endpoints
+// that anonymous clients can read.
+var endpoints = sourcehut.Endpoints{
+	Service: sourcehut.ServiceLists,
+	Public: map[string]bool{
+		"version":                            true,
+		"user/{username}":                    true,
+		"user/{username}/lists":              true,
+		"user/{username}/lists/{list}/posts": true,
+		"user/{username}/emails":             true,
+	},
+}
+
 // Option is used to configure an API client.
 type Option func(*Client) error
 
@@ -54,6 +68,17 @@ func Base(base string) Option {
 	}
 }
 
+// Instance returns an option that configures the client to use the lists API
+// of the provided Sourcehut instance and the sourcehut.Client that the instance
+// was created with.
+// Options that follow it, such as Base, override its settings.
+func Instance(inst *sourcehut.Instance) Option {
+	return func(c *Client) error {
+		c.srhtClient = inst.Client()
+		return Base(inst.BaseURL(endpoints.Service))(c)
+	}
+}
+
 // Client handles communication with the mailing lists related methods of the
 // Sourcehut API.
 //
@@ -89,73 +114,79 @@ func NewClient(opts ...Option) (*Client, error) {
 // Version returns the version of the API.
 //
 // API docs: https://man.sr.ht/api-conventions.md#get-apiversion
-func (c *Client) Version() (string, error) {
+func (c *Client) Version(ctx context.Context) (string, error) {
+	ctx = endpoints.Call(ctx, "version")
 	var ver struct {
 		Version string `json:"version"`
 	}
-	_, err := c.do("GET", "version", nil, &ver)
+	_, err := c.do(ctx, "GET", "version", nil, &ver)
 	return ver.Version, err
 }
 
 // List returns an iterator over all mailing lists owned by the provided
 // username.
 // If an empty username is provided, the authenticated user is used.
-func (c *Client) List(username string) (ListIter, error) {
-	path := "lists"
+func (c *Client) List(ctx context.Context, username string) (ListIter, error) {
+	path, endpoint := "lists", "lists"
 	if username != "" {
-		path = "user/" + url.PathEscape(username) + "/lists"
+		path, endpoint = "user/"+url.PathEscape(username)+"/lists", "user/{username}/lists"
 	}
-	return c.lists("GET", path, nil)
+	return c.lists(endpoints.Call(ctx, endpoint), "GET", path, nil)
 }
 
 // ListPosts returns the posts in a mailing list owned by the given username.
-func (c *Client) ListPosts(username, listname string) (PostIter, error) {
+func (c *Client) ListPosts(ctx context.Context, username, listname string) (PostIter, error) {
+	ctx = endpoints.Call(ctx, "user/{username}/lists/{list}/posts")
 	p := path.Join("user", username, "lists", listname, "posts")
-	return c.posts("GET", p, nil)
+	return c.posts(ctx, "GET", p, nil)
 }
 
 // GetUser returns information about the provided username, or the currently
 // authenticated user if the username is empty.
-func (c *Client) GetUser(username string) (sourcehut.User, error) {
+func (c *Client) GetUser(ctx context.Context, username string) (sourcehut.User, error) {
 	user := sourcehut.User{}
-	_, err := c.do("GET", path.Join("user", username), nil, &user)
+	_, err := c.do(endpoints.UserCall(ctx, username), "GET", path.Join("user", username), nil, &user)
 	return user, err
 }
 
 // ListEmails returns all emails sent by the provided user.
-func (c *Client) ListEmails(username string) (PostIter, error) {
-	return c.posts("GET", path.Join("user", username, "emails"), nil)
+func (c *Client) ListEmails(ctx context.Context, username string) (PostIter, error) {
+	ctx = endpoints.Call(ctx, "user/{username}/emails")
+	return c.posts(ctx, "GET", path.Join("user", username, "emails"), nil)
 }
 
-func (c *Client) do(method, u string, body io.Reader, v interface{}) (*http.Response, error) {
+func (c *Client) do(ctx context.Context, method, u string, body io.Reader, v interface{}) (*http.Response, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return nil, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return nil, err
 	}
 	return c.srhtClient.Do(req, v)
 }
 
-func (c *Client) lists(method, u string, body io.Reader) (ListIter, error) {
+func (c *Client) lists(ctx context.Context, method, u string, body io.Reader) (ListIter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return ListIter{}, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return ListIter{}, err
 	}
-	iter := c.srhtClient.List(req, func() interface{} {
-		return &List{}
-	})
-	return ListIter{Iter: iter}, nil
+	return ListIter{Iter: sourcehut.List[*List](c.srhtClient, req)}, nil
 }
 
-func (c *Client) posts(method, u string, body io.Reader) (PostIter, error) {
+func (c *Client) posts(ctx context.Context, method, u string, body io.Reader) (PostIter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return PostIter{}, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return PostIter{}, err
 	}
-	iter := c.srhtClient.List(req, func() interface{} {
-		return &Post{}
-	})
-	return PostIter{Iter: iter}, nil
+	return PostIter{Iter: sourcehut.List[*Post](c.srhtClient, req)}, nil
 }
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package lists_test
+
+import (
+	"context"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/replaytest"
+	"git.sr.ht/~wombelix/sourcehut-go/lists"
+)
+
+func newClient(t *testing.T, cassette string) *lists.Client {
+	t.Helper()
+	client, err := lists.NewClient(lists.SrhtClient(replaytest.Client(t, cassette)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestLists(t *testing.T) {
+	client := newClient(t, "lists")
+	ctx := context.Background()
+
+	ls, err := client.List(ctx, "~owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for l, err := range ls.All() {
+		if err != nil {
+			t.Fatalf("Error listing lists: %v", err)
+		}
+		names = append(names, l.Name)
+	}
+	if len(names) != 2 || names[0] != "devel" || names[1] != "announce" {
+		t.Errorf("Unexpected lists: %v", names)
+	}
+
+	posts, err := client.ListPosts(ctx, "~owner", "devel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subjects []string
+	for p, err := range posts.All() {
+		if err != nil {
+			t.Fatalf("Error listing posts: %v", err)
+		}
+		if p.List.Name != "devel" || p.Sender == nil {
+			t.Errorf("Unexpected post: %+v", p)
+		}
+		subjects = append(subjects, p.Subject)
+	}
+	if len(subjects) != 3 || subjects[0] != "[PATCH] First" {
+		t.Errorf("Unexpected posts: %v", subjects)
+	}
+
+	emails, err := client.ListEmails(ctx, "~sender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for p, err := range emails.All() {
+		if err != nil {
+			t.Fatalf("Error listing emails: %v", err)
+		}
+		if p.Sender.CanonicalName != "~sender" {
+			t.Errorf("Unexpected sender: %+v", p.Sender)
+		}
+		n++
+	}
+	if n != 4 {
+		t.Errorf("Expected 4 emails from ~sender, got %d", n)
+	}
+
+	user, err := client.GetUser(ctx, "~sender")
+	if err != nil || user.CanonicalName != "~sender" {
+		t.Errorf("Unexpected user %+v: %v", user, err)
+	}
+}
@@ -5,24 +5,28 @@
 package meta
 
 import (
+	"context"
 	"io"
 	"net/http"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
 )
 
 // ListAuditLog returns an iterator over all audit log entries available to the
 // authenticated user.
-func (c *Client) ListAuditLog() (AuditLogIter, error) {
-	return c.auditLogs("GET", "user/audit-log", nil)
+func (c *Client) ListAuditLog(ctx context.Context) (AuditLogIter, error) {
+	ctx = endpoints.Call(ctx, "user/audit-log")
+	return c.auditLogs(ctx, "GET", "user/audit-log", nil)
 }
 
-func (c *Client) auditLogs(method, u string, body io.Reader) (AuditLogIter, error) {
+func (c *Client) auditLogs(ctx context.Context, method, u string, body io.Reader) (AuditLogIter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return AuditLogIter{}, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return AuditLogIter{}, err
 	}
-	iter := c.srhtClient.List(req, func() interface{} {
-		return &AuditLog{}
-	})
-	return AuditLogIter{Iter: iter}, nil
+	return AuditLogIter{Iter: sourcehut.List[*AuditLog](c.srhtClient, req)}, nil
 }
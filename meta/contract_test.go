@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta_test
+
+import (
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/contract"
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+)
+
+func TestContract(t *testing.T) {
+	contract.Check(t, "testdata/contract.json", map[string]func() interface{}{
+		"User":                func() interface{} { return &meta.User{} },
+		"SSHKey":              func() interface{} { return &meta.SSHKey{} },
+		"PGPKey":              func() interface{} { return &meta.PGPKey{} },
+		"AuditLog":            func() interface{} { return &meta.AuditLog{} },
+		"PersonalAccessToken": func() interface{} { return &meta.PersonalAccessToken{} },
+		"OAuthClient":         func() interface{} { return &meta.OAuthClient{} },
+		"OAuthGrant":          func() interface{} { return &meta.OAuthGrant{} },
+		"Webhook":             func() interface{} { return &meta.Webhook{} },
+	})
+}
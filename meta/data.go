@@ -5,6 +5,7 @@
 package meta
 
 import (
+	"strings"
 	"time"
 
 	"git.sr.ht/~wombelix/sourcehut-go"
@@ -47,3 +48,50 @@ type AuditLog struct {
 	Details string    `json:"details"`
 	Created time.Time `json:"created"`
 }
+
+// PersonalAccessToken contains information about a personal access token.
+// The token itself is only available when it is issued.
+type PersonalAccessToken struct {
+	ID      int64     `json:"id"`
+	Issued  time.Time `json:"issued"`
+	Expires time.Time `json:"expires"`
+	Comment string    `json:"comment"`
+
+	// Grants is a space separated list of the scopes the token has access to
+	// (eg. "meta.sr.ht/PROFILE:RO").
+	// If it is empty the token has unrestricted access.
+	Grants string `json:"grants"`
+}
+
+// Scopes returns the scopes the token has access to.
+func (t PersonalAccessToken) Scopes() []string {
+	return strings.Fields(t.Grants)
+}
+
+// OAuthClient contains information about an OAuth application.
+type OAuthClient struct {
+	ID          int64  `json:"id"`
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	RedirectURL string `json:"redirectUrl"`
+}
+
+// OAuthGrant represents access to the user's account that has been granted to
+// an OAuth application.
+type OAuthGrant struct {
+	ID        int64       `json:"id"`
+	Client    OAuthClient `json:"client"`
+	Issued    time.Time   `json:"issued"`
+	Expires   time.Time   `json:"expires"`
+	TokenHash string      `json:"tokenHash"`
+}
+
+// Webhook is a subscription that delivers events to a URL.
+type Webhook struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created"`
+	Events  []Event   `json:"events"`
+	URL     string    `json:"url"`
+}
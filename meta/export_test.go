@@ -0,0 +1,18 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta
+
+// GraphQLDocuments is exposed in the test package only so that the documents
+// sent by the client can be checked against the schema.
+var GraphQLDocuments = map[string]string{
+	"ListTokens":          listTokensQuery,
+	"NewToken":            newTokenMutation,
+	"RevokeToken":         revokeTokenMutation,
+	"ListOAuthGrants":     listOAuthGrantsQuery,
+	"RevokeOAuthGrant":    revokeOAuthGrantMutation,
+	"ListOAuthClients":    listOAuthClientsQuery,
+	"RegisterOAuthClient": registerOAuthClientMutation,
+	"DeleteOAuthClient":   deleteOAuthClientMutation,
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta_test
+
+import (
+	"flag"
+	"slices"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/gqlcheck"
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+)
+
+var schema = flag.String("schema", "testdata/schema.graphqls", "check the GraphQL documents against the meta.sr.ht schema in this file")
+
+// internalOnly lists the fields used by each document that meta.sr.ht
+// restricts to internal clients, as documented on Client and on each method.
+var internalOnly = map[string][]string{
+	"ListTokens":          {"Query.personalAccessTokens @internal"},
+	"NewToken":            {"Mutation.issuePersonalAccessToken @internal"},
+	"RevokeToken":         {"Mutation.revokePersonalAccessToken @internal"},
+	"ListOAuthGrants":     {"OAuthGrant.tokenHash @internal", "Query.oauthGrants @internal"},
+	"RevokeOAuthGrant":    {"Mutation.revokeOAuthGrant @internal"},
+	"ListOAuthClients":    {"Query.oauthClients @internal"},
+	"RegisterOAuthClient": {"Mutation.registerOAuthClient @internal"},
+	"DeleteOAuthClient":   {"Mutation.revokeOAuthClient @internal"},
+}
+
+func TestGraphQLDocuments(t *testing.T) {
+	directives := gqlcheck.Check(t, *schema, meta.GraphQLDocuments)
+	for name, doc := range meta.GraphQLDocuments {
+		if doc == "" {
+			t.Errorf("%s: empty document", name)
+		}
+		d, ok := directives[name]
+		if !ok {
+			// The document was invalid and the failure has been reported.
+			continue
+		}
+		if want := internalOnly[name]; !slices.Equal(d, want) {
+			t.Errorf("%s: fields with directives: want=%q, got=%q", name, want, d)
+		}
+	}
+}
@@ -10,30 +10,65 @@ import (
 
 // SSHKeyIter is used for iterating over the account's authorized SSH keys.
 type SSHKeyIter struct {
-	*sourcehut.Iter
+	*sourcehut.Iter[*SSHKey]
 }
 
 // Key returns the SSH key which the iterator is currently pointing to.
+// If there is no current item, such as before the first call to Next or if the
+// item was null in the response, it returns the zero value.
 func (i SSHKeyIter) Key() SSHKey {
-	return *(i.Current().(*SSHKey))
+	v := i.Current()
+	if v == nil {
+		return SSHKey{}
+	}
+	return *v
 }
 
 // PGPKeyIter is used for iterating over the account's PGP keys.
 type PGPKeyIter struct {
-	*sourcehut.Iter
+	*sourcehut.Iter[*PGPKey]
 }
 
 // Key returns the PGP key which the iterator is currently pointing to.
+// If there is no current item, such as before the first call to Next or if the
+// item was null in the response, it returns the zero value.
 func (i PGPKeyIter) Key() PGPKey {
-	return *(i.Current().(*PGPKey))
+	v := i.Current()
+	if v == nil {
+		return PGPKey{}
+	}
+	return *v
 }
 
 // AuditLogIter is used for iterating over the account's PGP keys.
 type AuditLogIter struct {
-	*sourcehut.Iter
+	*sourcehut.Iter[*AuditLog]
 }
 
 // Log returns the audit log entry which the iterator is currently pointing to.
+// If there is no current item, such as before the first call to Next or if the
+// item was null in the response, it returns the zero value.
 func (i AuditLogIter) Log() AuditLog {
-	return *(i.Current().(*AuditLog))
+	v := i.Current()
+	if v == nil {
+		return AuditLog{}
+	}
+	return *v
+}
+
+// WebhookIter is used for iterating over the account's webhook subscriptions.
+type WebhookIter struct {
+	*sourcehut.Iter[*Webhook]
+}
+
+// Webhook returns the subscription which the iterator is currently pointing
+// to.
+// If there is no current item, such as before the first call to Next or if the
+// item was null in the response, it returns the zero value.
+func (i WebhookIter) Webhook() Webhook {
+	v := i.Current()
+	if v == nil {
+		return Webhook{}
+	}
+	return *v
 }
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+)
+
+func TestIterNoCurrent(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		/* #nosec */
+		_, _ = io.WriteString(w, `{"results": [null], "next": null}`)
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+
+	client, err := meta.NewClient(
+		meta.SrhtClient(sourcehut.NewClient(sourcehut.Token("123"), sourcehut.UserAgent("test"))),
+		meta.Base(server.URL),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := client.ListSSHKeys(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := keys.Key(); k != (meta.SSHKey{}) {
+		t.Errorf("Expected zero key before Next, got %+v", k)
+	}
+	if !keys.Next() {
+		t.Fatalf("Expected an item, got error: %v", keys.Err())
+	}
+	if k := keys.Key(); k != (meta.SSHKey{}) {
+		t.Errorf("Expected zero key for null item, got %+v", k)
+	}
+}
@@ -7,6 +7,7 @@ package meta
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -20,6 +21,15 @@ import (
 // It is exported for convenience.
 const BaseURL = "https://meta.sr.ht/api/"
 
+// endpoints identifies the calls made by the client and lists the endpoints
+// that anonymous clients can read.
+var endpoints = sourcehut.Endpoints{
+	Service: sourcehut.ServiceMeta,
+	Public: map[string]bool{
+		"version": true,
+	},
+}
+
 // Option is used to configure an API client.
 type Option func(*Client) error
 
@@ -55,9 +65,30 @@ func Base(base string) Option {
 	}
 }
 
+// Instance returns an option that configures the client to use the meta API
+// of the provided Sourcehut instance and the sourcehut.Client that the instance
+// was created with.
+// Options that follow it, such as Base, override its settings.
+func Instance(inst *sourcehut.Instance) Option {
+	return func(c *Client) error {
+		c.srhtClient = inst.Client()
+		return Base(inst.BaseURL(endpoints.Service))(c)
+	}
+}
+
 // Client handles communication with the user related methods of the Sourcehut
 // API.
 //
+// Personal access tokens and OAuth applications are only available through the
+// GraphQL API, which the client expects to find at "../query" relative to the
+// base URL.
+// The meta.sr.ht schema marks the fields that manage them as internal: the
+// server rejects them unless the request uses the internal authentication of
+// the sr.ht services, so the methods that use them fail with an error when the
+// client is authenticated with a personal access token or an OAuth 2.0 token.
+// They work with a RoundTripper that adds internal authentication, and with the
+// srhttest package.
+//
 // API docs: https://man.sr.ht/meta.sr.ht/user-api.md
 type Client struct {
 	baseURL    *url.URL
@@ -90,18 +121,20 @@ func NewClient(opts ...Option) (*Client, error) {
 // Version returns the version of the API.
 //
 // API docs: https://man.sr.ht/api-conventions.md#get-apiversion
-func (c *Client) Version() (string, error) {
+func (c *Client) Version(ctx context.Context) (string, error) {
+	ctx = endpoints.Call(ctx, "version")
 	var ver struct {
 		Version string `json:"version"`
 	}
-	_, err := c.do("GET", "version", "", nil, &ver)
+	_, err := c.do(ctx, "GET", "version", "", nil, &ver)
 	return ver.Version, err
 }
 
 // GetUser returns information about the currently authenticated user.
-func (c *Client) GetUser() (User, error) {
+func (c *Client) GetUser(ctx context.Context) (User, error) {
+	ctx = endpoints.Call(ctx, "user/profile")
 	user := User{}
-	_, err := c.do("GET", "user/profile", "", nil, &user)
+	_, err := c.do(ctx, "GET", "user/profile", "", nil, &user)
 	return user, err
 }
 
@@ -117,19 +150,23 @@ type ProfileParams struct {
 // UpdateUser sets information about the user.
 // Nil values indicate that the field should not be updated.
 // If the email field is updated it will trigger a confirmation email.
-func (c *Client) UpdateUser(user ProfileParams) (User, error) {
+func (c *Client) UpdateUser(ctx context.Context, user ProfileParams) (User, error) {
+	ctx = endpoints.Call(ctx, "user/profile")
 	newUser := User{}
 	j, err := json.Marshal(user)
 	if err != nil {
 		return newUser, err
 	}
-	_, err = c.do("PUT", "user/profile", "application/json", bytes.NewReader(j), &newUser)
+	_, err = c.do(ctx, "PUT", "user/profile", "application/json", bytes.NewReader(j), &newUser)
 	return newUser, err
 }
 
-func (c *Client) do(method, u, contentType string, body io.Reader, v interface{}) (*http.Response, error) {
+func (c *Client) do(ctx context.Context, method, u, contentType string, body io.Reader, v interface{}) (*http.Response, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return nil, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return nil, err
 	}
@@ -138,3 +175,15 @@ func (c *Client) do(method, u, contentType string, body io.Reader, v interface{}
 	}
 	return c.srhtClient.Do(req, v)
 }
+
+// query sends a query to the GraphQL API of the service, which is served at
+// "query" next to the legacy API (eg. https://meta.sr.ht/query).
+//
+// API docs: https://man.sr.ht/meta.sr.ht/graphql.md
+func (c *Client) query(ctx context.Context, query string, vars map[string]interface{}, v interface{}) error {
+	if err := endpoints.Authorize(ctx, c.srhtClient, "POST"); err != nil {
+		return err
+	}
+	endpoint := c.baseURL.ResolveReference(&url.URL{Path: "../query"})
+	return sourcehut.NewGraphQL(c.srhtClient, endpoint.String()).Query(ctx, query, vars, v)
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta
+
+import (
+	"context"
+	"fmt"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+)
+
+const clientFields = `id uuid name description url redirectUrl`
+
+// The GraphQL documents used to manage OAuth applications and grants.
+const (
+	listOAuthGrantsQuery = `query {
+	oauthGrants {
+		id issued expires tokenHash
+		client { ` + clientFields + ` }
+	}
+}`
+
+	revokeOAuthGrantMutation = `mutation($hash: String!) { revokeOAuthGrant(hash: $hash) { id } }`
+
+	listOAuthClientsQuery = `query { oauthClients { ` + clientFields + ` } }`
+
+	registerOAuthClientMutation = `mutation($redirectUri: String!, $name: String!, $description: String, $url: String) {
+	registerOAuthClient(redirectUri: $redirectUri, clientName: $name, clientDescription: $description, clientUrl: $url) {
+		client { ` + clientFields + ` }
+		secret
+	}
+}`
+
+	deleteOAuthClientMutation = `mutation($uuid: String!) { revokeOAuthClient(uuid: $uuid) { id } }`
+)
+
+// ListOAuthGrants returns the OAuth applications that have been granted access
+// to the authenticated user's account.
+// It requires internal authentication and fails with personal access tokens
+// and OAuth 2.0 tokens, see Client.
+func (c *Client) ListOAuthGrants(ctx context.Context) ([]OAuthGrant, error) {
+	ctx = endpoints.Call(ctx, "oauthGrants")
+	var data struct {
+		Grants []OAuthGrant `json:"oauthGrants"`
+	}
+	err := c.query(ctx, listOAuthGrantsQuery, nil, &data)
+	return data.Grants, err
+}
+
+// RevokeOAuthGrant revokes the access of an OAuth application to the
+// authenticated user's account.
+// The grant is identified by the hash of its token.
+// It requires internal authentication and fails with personal access tokens
+// and OAuth 2.0 tokens, see Client.
+func (c *Client) RevokeOAuthGrant(ctx context.Context, tokenHash string) error {
+	ctx = endpoints.Call(ctx, "revokeOAuthGrant")
+	var data struct {
+		Grant *struct {
+			ID int64 `json:"id"`
+		} `json:"revokeOAuthGrant"`
+	}
+	err := c.query(ctx, revokeOAuthGrantMutation, map[string]interface{}{"hash": tokenHash}, &data)
+	if err == nil && data.Grant == nil {
+		err = fmt.Errorf("OAuth grant %s: %w", tokenHash, sourcehut.ErrNotFound)
+	}
+	return err
+}
+
+// ListOAuthClients returns the OAuth applications registered by the
+// authenticated user.
+// It requires internal authentication and fails with personal access tokens
+// and OAuth 2.0 tokens, see Client.
+func (c *Client) ListOAuthClients(ctx context.Context) ([]OAuthClient, error) {
+	ctx = endpoints.Call(ctx, "oauthClients")
+	var data struct {
+		Clients []OAuthClient `json:"oauthClients"`
+	}
+	err := c.query(ctx, listOAuthClientsQuery, nil, &data)
+	return data.Clients, err
+}
+
+// OAuthClientParams is used to register an OAuth application.
+// Name and RedirectURL are required.
+type OAuthClientParams struct {
+	Name        string
+	Description string
+	URL         string
+	RedirectURL string
+}
+
+// RegisterOAuthClient registers a new OAuth application.
+//
+// The client secret is returned along with the application and cannot be
+// retrieved again later.
+// It requires internal authentication and fails with personal access tokens
+// and OAuth 2.0 tokens, see Client.
+func (c *Client) RegisterOAuthClient(ctx context.Context, params OAuthClientParams) (OAuthClient, string, error) {
+	ctx = endpoints.Call(ctx, "registerOAuthClient")
+	vars := map[string]interface{}{
+		"name":        params.Name,
+		"redirectUri": params.RedirectURL,
+	}
+	if params.Description != "" {
+		vars["description"] = params.Description
+	}
+	if params.URL != "" {
+		vars["url"] = params.URL
+	}
+	var data struct {
+		Registration struct {
+			Client OAuthClient `json:"client"`
+			Secret string      `json:"secret"`
+		} `json:"registerOAuthClient"`
+	}
+	err := c.query(ctx, registerOAuthClientMutation, vars, &data)
+	return data.Registration.Client, data.Registration.Secret, err
+}
+
+// DeleteOAuthClient deletes the OAuth application with the provided UUID and
+// revokes all access that was granted to it.
+// It requires internal authentication and fails with personal access tokens
+// and OAuth 2.0 tokens, see Client.
+func (c *Client) DeleteOAuthClient(ctx context.Context, uuid string) error {
+	ctx = endpoints.Call(ctx, "revokeOAuthClient")
+	var data struct {
+		Client *struct {
+			ID int64 `json:"id"`
+		} `json:"revokeOAuthClient"`
+	}
+	err := c.query(ctx, deleteOAuthClientMutation, map[string]interface{}{"uuid": uuid}, &data)
+	if err == nil && data.Client == nil {
+		err = fmt.Errorf("OAuth client %s: %w", uuid, sourcehut.ErrNotFound)
+	}
+	return err
+}
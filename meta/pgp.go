@@ -6,28 +6,34 @@ package meta
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"strconv"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
 )
 
 // GetPGPKey returns the PGP key with the provided ID.
-func (c *Client) GetPGPKey(id int64) (PGPKey, error) {
+func (c *Client) GetPGPKey(ctx context.Context, id int64) (PGPKey, error) {
+	ctx = endpoints.Call(ctx, "user/pgp-keys/{id}")
 	key := PGPKey{}
-	_, err := c.do("GET", "user/pgp-keys/"+strconv.FormatInt(id, 10), "", nil, &key)
+	_, err := c.do(ctx, "GET", "user/pgp-keys/"+strconv.FormatInt(id, 10), "", nil, &key)
 	return key, err
 }
 
 // DeletePGPKey deletes the PGP key with the provided ID.
-func (c *Client) DeletePGPKey(id int64) error {
-	_, err := c.do("DELETE", "user/pgp-keys/"+strconv.FormatInt(id, 10), "", nil, nil)
+func (c *Client) DeletePGPKey(ctx context.Context, id int64) error {
+	ctx = endpoints.Call(ctx, "user/pgp-keys/{id}")
+	_, err := c.do(ctx, "DELETE", "user/pgp-keys/"+strconv.FormatInt(id, 10), "", nil, nil)
 	return err
 }
 
 // NewPGPKey creates a new PGP key.
 // The key should be in authorized_keys format.
-func (c *Client) NewPGPKey(k string) (PGPKey, error) {
+func (c *Client) NewPGPKey(ctx context.Context, k string) (PGPKey, error) {
+	ctx = endpoints.Call(ctx, "user/pgp-keys")
 	key := PGPKey{}
 	jsonKey, err := json.Marshal(struct {
 		Key string `json:"pgp-key"`
@@ -38,24 +44,25 @@ func (c *Client) NewPGPKey(k string) (PGPKey, error) {
 		return key, err
 	}
 
-	_, err = c.do("POST", "user/pgp-keys", "application/json", bytes.NewReader(jsonKey), &key)
+	_, err = c.do(ctx, "POST", "user/pgp-keys", "application/json", bytes.NewReader(jsonKey), &key)
 	return key, err
 }
 
 // ListPGPKeys returns an iterator over all PGP keys authorized on the users
 // account.
-func (c *Client) ListPGPKeys() (PGPKeyIter, error) {
-	return c.pgpKeys("GET", "user/pgp-keys", nil)
+func (c *Client) ListPGPKeys(ctx context.Context) (PGPKeyIter, error) {
+	ctx = endpoints.Call(ctx, "user/pgp-keys")
+	return c.pgpKeys(ctx, "GET", "user/pgp-keys", nil)
 }
 
-func (c *Client) pgpKeys(method, u string, body io.Reader) (PGPKeyIter, error) {
+func (c *Client) pgpKeys(ctx context.Context, method, u string, body io.Reader) (PGPKeyIter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return PGPKeyIter{}, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return PGPKeyIter{}, err
 	}
-	iter := c.srhtClient.List(req, func() interface{} {
-		return &PGPKey{}
-	})
-	return PGPKeyIter{Iter: iter}, nil
+	return PGPKeyIter{Iter: sourcehut.List[*PGPKey](c.srhtClient, req)}, nil
 }
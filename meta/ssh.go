@@ -6,28 +6,36 @@ package meta
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"strconv"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
 )
 
 // GetSSHKey returns the SSH key with the provided ID.
-func (c *Client) GetSSHKey(id int64) (SSHKey, error) {
+func (c *Client) GetSSHKey(ctx context.Context, id int64) (SSHKey, error) {
+	ctx = endpoints.Call(ctx, "user/ssh-keys/{id}")
 	key := SSHKey{}
-	_, err := c.do("GET", "user/ssh-keys/"+strconv.FormatInt(id, 10), "", nil, &key)
+	_, err := c.do(ctx, "GET", "user/ssh-keys/"+strconv.FormatInt(id, 10), "", nil, &key)
 	return key, err
 }
 
 // DeleteSSHKey deletes the SSH key with the provided ID.
-func (c *Client) DeleteSSHKey(id int64) error {
-	_, err := c.do("DELETE", "user/ssh-keys/"+strconv.FormatInt(id, 10), "", nil, nil)
+func (c *Client) DeleteSSHKey(ctx context.Context, id int64) error {
+	ctx = endpoints.Call(ctx, "user/ssh-keys/{id}")
+	_, err := c.do(ctx, "DELETE", "user/ssh-keys/"+strconv.FormatInt(id, 10), "", nil, nil)
 	return err
 }
 
 // NewSSHKey creates a new SSH key.
-// The key should be in authorized_keys format.
-func (c *Client) NewSSHKey(k string) (SSHKey, error) {
+// The key should be in authorized_keys format, without any options.
+// Keys are only validated by the server; use ParseAuthorizedKey to check a key
+// and get a descriptive error before uploading it.
+func (c *Client) NewSSHKey(ctx context.Context, k string) (SSHKey, error) {
+	ctx = endpoints.Call(ctx, "user/ssh-keys")
 	key := SSHKey{}
 	jsonKey, err := json.Marshal(struct {
 		Key string `json:"ssh-key"`
@@ -38,24 +46,25 @@ func (c *Client) NewSSHKey(k string) (SSHKey, error) {
 		return key, err
 	}
 
-	_, err = c.do("POST", "user/ssh-keys", "application/json", bytes.NewReader(jsonKey), &key)
+	_, err = c.do(ctx, "POST", "user/ssh-keys", "application/json", bytes.NewReader(jsonKey), &key)
 	return key, err
 }
 
 // ListSSHKeys returns an iterator over all SSH keys authorized on the users
 // account.
-func (c *Client) ListSSHKeys() (SSHKeyIter, error) {
-	return c.sshKeys("GET", "user/ssh-keys", nil)
+func (c *Client) ListSSHKeys(ctx context.Context) (SSHKeyIter, error) {
+	ctx = endpoints.Call(ctx, "user/ssh-keys")
+	return c.sshKeys(ctx, "GET", "user/ssh-keys", nil)
 }
 
-func (c *Client) sshKeys(method, u string, body io.Reader) (SSHKeyIter, error) {
+func (c *Client) sshKeys(ctx context.Context, method, u string, body io.Reader) (SSHKeyIter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return SSHKeyIter{}, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return SSHKeyIter{}, err
 	}
-	iter := c.srhtClient.List(req, func() interface{} {
-		return &SSHKey{}
-	})
-	return SSHKeyIter{Iter: iter}, nil
+	return SSHKeyIter{Iter: sourcehut.List[*SSHKey](c.srhtClient, req)}, nil
 }
@@ -0,0 +1,252 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta
+
+import (
+	"bufio"
+	"context"
+	/* #nosec */
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidSSHKey is returned when an SSH public key cannot be parsed.
+var ErrInvalidSSHKey = errors.New("meta: invalid SSH key")
+
+// AuthorizedKey is an SSH public key in the format used by OpenSSH's
+// authorized_keys file.
+type AuthorizedKey struct {
+	// Options restrict the key (eg. `no-pty` or `command="uptime"`).
+	// Meta does not support options, so they must be removed before a key is
+	// uploaded.
+	Options []string
+
+	// Type is the key type (eg. "ssh-ed25519").
+	Type string
+
+	// Blob is the decoded public key in the SSH wire format.
+	Blob []byte
+
+	Comment string
+}
+
+// ParseAuthorizedKey parses a single line in authorized_keys format:
+//
+//	[options] keytype base64-key [comment]
+//
+// The encoded key is checked to be well formed for its type.
+func ParseAuthorizedKey(line string) (AuthorizedKey, error) {
+	var k AuthorizedKey
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return k, fmt.Errorf("%w: empty line", ErrInvalidSSHKey)
+	}
+	fields := strings.Fields(line)
+	if !isKeyType(fields[0]) {
+		opts, rest, err := splitOptions(line)
+		if err != nil {
+			return k, err
+		}
+		k.Options = opts
+		line = rest
+		fields = strings.Fields(line)
+	}
+	if len(fields) < 2 {
+		return k, fmt.Errorf("%w: missing key", ErrInvalidSSHKey)
+	}
+	k.Type = fields[0]
+	if !isKeyType(k.Type) {
+		return k, fmt.Errorf("%w: unknown key type %q", ErrInvalidSSHKey, k.Type)
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return k, fmt.Errorf("%w: key is not valid base64", ErrInvalidSSHKey)
+	}
+	if err = checkBlob(k.Type, blob); err != nil {
+		return k, err
+	}
+	k.Blob = blob
+	// The comment is everything after the key, which may contain spaces.
+	_, comment, _ := strings.Cut(line, fields[1])
+	k.Comment = strings.TrimSpace(comment)
+	return k, nil
+}
+
+// ParseAuthorizedKeys parses every key in an authorized_keys file.
+// Blank lines and lines starting with "#" are skipped.
+func ParseAuthorizedKeys(r io.Reader) ([]AuthorizedKey, error) {
+	var keys []AuthorizedKey
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, err := ParseAuthorizedKey(line)
+		if err != nil {
+			return keys, fmt.Errorf("line %d: %w", n, err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, s.Err()
+}
+
+// String returns the key in authorized_keys format without its options, which
+// is the format expected by NewSSHKey.
+func (k AuthorizedKey) String() string {
+	s := k.Type + " " + base64.StdEncoding.EncodeToString(k.Blob)
+	if k.Comment != "" {
+		s += " " + k.Comment
+	}
+	return s
+}
+
+// FingerprintSHA256 returns the SHA256 fingerprint of the key in the format
+// used by OpenSSH (eg. "SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s").
+func (k AuthorizedKey) FingerprintSHA256() string {
+	sum := sha256.Sum256(k.Blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// FingerprintMD5 returns the legacy MD5 fingerprint of the key as colon
+// separated hex bytes (eg. "c1:b1:30:29:d7:b8:de:6c:97:77:10:d7:46:41:63:87").
+func (k AuthorizedKey) FingerprintMD5() string {
+	/* #nosec */
+	sum := md5.Sum(k.Blob)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = hex.EncodeToString([]byte{b})
+	}
+	return strings.Join(parts, ":")
+}
+
+// Matches reports whether fingerprint, such as SSHKey.Fingerprint, is the SHA256
+// or MD5 fingerprint of the key.
+func (k AuthorizedKey) Matches(fingerprint string) bool {
+	if strings.HasPrefix(fingerprint, "SHA256:") {
+		return fingerprint == k.FingerprintSHA256()
+	}
+	return strings.EqualFold(strings.TrimPrefix(fingerprint, "MD5:"), k.FingerprintMD5())
+}
+
+// FindSSHKey looks for key in the SSH keys authorized on the user's account by
+// comparing fingerprints, and returns the registered key if it is found.
+func (c *Client) FindSSHKey(ctx context.Context, key AuthorizedKey) (SSHKey, bool, error) {
+	iter, err := c.ListSSHKeys(ctx)
+	if err != nil {
+		return SSHKey{}, false, err
+	}
+	for registered, err := range iter.All() {
+		if err != nil {
+			return SSHKey{}, false, err
+		}
+		if key.Matches(registered.Fingerprint) {
+			return *registered, true, nil
+		}
+	}
+	return SSHKey{}, false, nil
+}
+
+const (
+	skEd25519 = "sk-ssh-ed25519@openssh.com"
+	skECDSA   = "sk-ecdsa-sha2-nistp256@openssh.com"
+)
+
+func isKeyType(t string) bool {
+	switch t {
+	case "ssh-ed25519", "ssh-rsa", "ssh-dss",
+		"ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521",
+		skEd25519, skECDSA:
+		return true
+	}
+	return strings.HasSuffix(t, "-cert-v01@openssh.com")
+}
+
+// checkBlob checks that the key in the SSH wire format has the fields expected
+// for its type.
+// Only the type of certificates is checked.
+func checkBlob(keyType string, blob []byte) error {
+	var fields [][]byte
+	for len(blob) > 0 {
+		if len(blob) < 4 {
+			return fmt.Errorf("%w: truncated key", ErrInvalidSSHKey)
+		}
+		n := binary.BigEndian.Uint32(blob)
+		if uint64(n) > uint64(len(blob)-4) {
+			return fmt.Errorf("%w: truncated key", ErrInvalidSSHKey)
+		}
+		fields = append(fields, blob[4:4+n])
+		blob = blob[4+n:]
+		if strings.HasSuffix(keyType, "-cert-v01@openssh.com") {
+			break
+		}
+	}
+	if len(fields) == 0 || string(fields[0]) != keyType {
+		return fmt.Errorf("%w: encoded key is not of type %s", ErrInvalidSSHKey, keyType)
+	}
+
+	want := 0
+	switch keyType {
+	case "ssh-ed25519":
+		want = 2
+	case "ssh-rsa", skEd25519:
+		want = 3
+	case "ssh-dss":
+		want = 5
+	case "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521":
+		want = 3
+	case skECDSA:
+		want = 4
+	}
+	if want != 0 && len(fields) != want {
+		return fmt.Errorf("%w: malformed %s key", ErrInvalidSSHKey, keyType)
+	}
+	switch keyType {
+	case "ssh-ed25519", skEd25519:
+		if len(fields[1]) != 32 {
+			return fmt.Errorf("%w: malformed %s key", ErrInvalidSSHKey, keyType)
+		}
+	case "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521", skECDSA:
+		if curve := strings.TrimPrefix(strings.TrimSuffix(keyType, "@openssh.com"), "sk-"); "ecdsa-sha2-"+string(fields[1]) != curve {
+			return fmt.Errorf("%w: malformed %s key", ErrInvalidSSHKey, keyType)
+		}
+	}
+	return nil
+}
+
+// splitOptions splits the options at the start of an authorized_keys line from
+// the rest of the line.
+// Options are separated by commas and may contain quoted strings with spaces.
+func splitOptions(line string) ([]string, string, error) {
+	var (
+		opts   []string
+		start  int
+		quoted bool
+	)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			opts = append(opts, line[start:i])
+			start = i + 1
+		case (c == ' ' || c == '\t') && !quoted:
+			return append(opts, line[start:i]), line[i:], nil
+		}
+	}
+	if quoted {
+		return nil, "", fmt.Errorf("%w: unterminated quote in options", ErrInvalidSSHKey)
+	}
+	return nil, "", fmt.Errorf("%w: missing key", ErrInvalidSSHKey)
+}
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+)
+
+const (
+	ed25519Key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICf9nXGV4cuw2QVU+d7RpX+a3L3J03Eh/MdAxop0cecR"
+	rsaKey     = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCXzfiItFdzclJQDUrxkDP7O982c/LclLlMOYaWaUGHc/0vyq1SP4CFdwTPXbvUMCcf6iTY6TD+CZ3j1okP4WTQX0y/Mo52deoMXg5xHZd7nKigP0Ha0yGJllINMFEGmnOm8/dqpqajU0xyCuX5/I4XLivqfkqsQIAtcO/XwFHg/J15B00/Slqa7Ucicnk4I1zgbT3Di0VI40trV3HP23p9cNXspFDizMnT+uzp3n+04N91/SbYM9MdFFSraIT8bJaU8VuxPTCz7KIPEfQUT3faWmPDH2+XkEc54RANlH188Exd43o2eJFSPkCguXm7q8rDIKhWQXiwwMn0Nr12mTUn"
+	ecdsaKey   = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBAd2lrGD5ALPXOkSbSMK7oa4Pobpce8dTFtZztNqkW7bNm6a/MGscKvsKBVCCYfr/n1O+hY0Da/80g15/csFMtE="
+)
+
+// Fingerprints were generated using ssh-keygen -l.
+var parseAuthorizedKeyTests = [...]struct {
+	line    string
+	options []string
+	typ     string
+	comment string
+	sha256  string
+	md5     string
+	err     bool
+}{
+	0: {
+		line:    ed25519Key + " test@example",
+		typ:     "ssh-ed25519",
+		comment: "test@example",
+		sha256:  "SHA256:c7vr8zu9PluQGmvv0ocT0qAnH97OzPiMWWK45kUiQgA",
+		md5:     "a8:80:2e:29:cb:ba:d9:14:5a:d0:5d:6b:f9:8e:cc:8a",
+	},
+	1: {
+		line:    "  " + rsaKey + "  rsa key\n",
+		typ:     "ssh-rsa",
+		comment: "rsa key",
+		sha256:  "SHA256:WJ/Fb963pU3aA82AYgo+YEISSev3QfKfDEnU6BujB/U",
+		md5:     "a7:28:09:7d:87:bc:18:51:ef:d4:51:83:47:7e:c6:00",
+	},
+	2: {
+		line:   ecdsaKey,
+		typ:    "ecdsa-sha2-nistp256",
+		sha256: "SHA256:zj3g+umavm70oxEkD17KMZwLdWs1lwWXUc9UydKLvQQ",
+		md5:    "68:ec:e8:4d:72:7f:34:78:d9:77:48:10:85:54:6f:ef",
+	},
+	3: {
+		line:    `no-pty,command="echo \"a, b\"",from="192.0.2.1" ` + ed25519Key + " ci",
+		options: []string{"no-pty", `command="echo \"a, b\""`, `from="192.0.2.1"`},
+		typ:     "ssh-ed25519",
+		comment: "ci",
+		sha256:  "SHA256:c7vr8zu9PluQGmvv0ocT0qAnH97OzPiMWWK45kUiQgA",
+		md5:     "a8:80:2e:29:cb:ba:d9:14:5a:d0:5d:6b:f9:8e:cc:8a",
+	},
+	4:  {line: "", err: true},
+	5:  {line: "garbage", err: true},
+	6:  {line: "ssh-ed25519", err: true},
+	7:  {line: "ssh-ed25519 not-base64!", err: true},
+	8:  {line: "ssh-rsa " + strings.Fields(ed25519Key)[1], err: true},
+	9:  {line: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGFrZWtleWZha2VrZXlmYWtla2V5ZmFrZWtleWZh", err: true},
+	10: {line: `command="unterminated ` + ed25519Key, err: true},
+	11: {line: "ssh-foo AAAAB3NzaC1yc2E=", err: true},
+}
+
+func TestParseAuthorizedKey(t *testing.T) {
+	for i, tc := range parseAuthorizedKeyTests {
+		k, err := meta.ParseAuthorizedKey(tc.line)
+		switch {
+		case tc.err && !errors.Is(err, meta.ErrInvalidSSHKey):
+			t.Errorf("%d: expected invalid key error, got: %v", i, err)
+			continue
+		case tc.err:
+			continue
+		case err != nil:
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(k.Options, tc.options) {
+			t.Errorf("%d: wrong options: want=%q, got=%q", i, tc.options, k.Options)
+		}
+		if k.Type != tc.typ || k.Comment != tc.comment {
+			t.Errorf("%d: wrong type or comment: want=%q %q, got=%q %q", i, tc.typ, tc.comment, k.Type, k.Comment)
+		}
+		if fp := k.FingerprintSHA256(); fp != tc.sha256 {
+			t.Errorf("%d: wrong SHA256 fingerprint: want=%s, got=%s", i, tc.sha256, fp)
+		}
+		if fp := k.FingerprintMD5(); fp != tc.md5 {
+			t.Errorf("%d: wrong MD5 fingerprint: want=%s, got=%s", i, tc.md5, fp)
+		}
+		for _, fp := range []string{tc.sha256, tc.md5, "MD5:" + strings.ToUpper(tc.md5)} {
+			if !k.Matches(fp) {
+				t.Errorf("%d: key does not match fingerprint %s", i, fp)
+			}
+		}
+		if k.Matches("SHA256:" + tc.md5) {
+			t.Errorf("%d: key matches wrong fingerprint", i)
+		}
+		s := k.String()
+		k2, err := meta.ParseAuthorizedKey(s)
+		if err != nil || k2.Options != nil || k2.Type != k.Type || k2.Comment != k.Comment || !reflect.DeepEqual(k2.Blob, k.Blob) {
+			t.Errorf("%d: string %q does not parse to the same key without options: %v", i, s, err)
+		}
+	}
+}
+
+func TestParseAuthorizedKeys(t *testing.T) {
+	keys, err := meta.ParseAuthorizedKeys(strings.NewReader("# keys\n\n" + ed25519Key + "\n" + rsaKey + " rsa\n"))
+	if err != nil || len(keys) != 2 || keys[1].Comment != "rsa" {
+		t.Errorf("Unexpected keys %+v: %v", keys, err)
+	}
+	_, err = meta.ParseAuthorizedKeys(strings.NewReader(ed25519Key + "\ngarbage\n"))
+	if !errors.Is(err, meta.ErrInvalidSSHKey) || !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Errorf("Expected invalid key error on line 2, got: %v", err)
+	}
+}
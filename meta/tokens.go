@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+)
+
+const tokenFields = `id issued expires comment grants`
+
+// The GraphQL documents used to manage personal access tokens.
+const (
+	listTokensQuery = `query { personalAccessTokens { ` + tokenFields + ` } }`
+
+	newTokenMutation = `mutation($grants: String, $comment: String) {
+	issuePersonalAccessToken(grants: $grants, comment: $comment) {
+		token { ` + tokenFields + ` }
+		secret
+	}
+}`
+
+	revokeTokenMutation = `mutation($id: Int!) { revokePersonalAccessToken(id: $id) { id } }`
+)
+
+// ListTokens returns the personal access tokens issued to the authenticated
+// user.
+// It requires internal authentication and fails with personal access tokens
+// and OAuth 2.0 tokens, see Client.
+func (c *Client) ListTokens(ctx context.Context) ([]PersonalAccessToken, error) {
+	ctx = endpoints.Call(ctx, "personalAccessTokens")
+	var data struct {
+		Tokens []PersonalAccessToken `json:"personalAccessTokens"`
+	}
+	err := c.query(ctx, listTokensQuery, nil, &data)
+	return data.Tokens, err
+}
+
+// NewToken issues a new personal access token with the provided comment that
+// has access to scopes (eg. "meta.sr.ht/PROFILE:RO").
+// If no scopes are provided the token has unrestricted access.
+//
+// The secret that is used to authenticate with the token is returned along with
+// it and cannot be retrieved again later.
+// It requires internal authentication and fails with personal access tokens
+// and OAuth 2.0 tokens, see Client.
+func (c *Client) NewToken(ctx context.Context, comment string, scopes ...string) (PersonalAccessToken, string, error) {
+	ctx = endpoints.Call(ctx, "issuePersonalAccessToken")
+	vars := map[string]interface{}{"comment": comment}
+	if len(scopes) > 0 {
+		vars["grants"] = strings.Join(scopes, " ")
+	}
+	var data struct {
+		Registration struct {
+			Token  PersonalAccessToken `json:"token"`
+			Secret string              `json:"secret"`
+		} `json:"issuePersonalAccessToken"`
+	}
+	err := c.query(ctx, newTokenMutation, vars, &data)
+	return data.Registration.Token, data.Registration.Secret, err
+}
+
+// RevokeToken revokes the personal access token with the provided ID.
+// It requires internal authentication and fails with personal access tokens
+// and OAuth 2.0 tokens, see Client.
+func (c *Client) RevokeToken(ctx context.Context, id int64) error {
+	ctx = endpoints.Call(ctx, "revokePersonalAccessToken")
+	var data struct {
+		Token *struct {
+			ID int64 `json:"id"`
+		} `json:"revokePersonalAccessToken"`
+	}
+	err := c.query(ctx, revokeTokenMutation, map[string]interface{}{"id": id}, &data)
+	if err == nil && data.Token == nil {
+		err = fmt.Errorf("personal access token %d: %w", id, sourcehut.ErrNotFound)
+	}
+	return err
+}
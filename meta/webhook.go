@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/webhooksig"
+)
+
+// Event is the name of an event that webhooks can subscribe to.
+type Event string
+
+// Events that are delivered by the meta service.
+const (
+	EventProfileUpdate Event = "profile:update"
+	EventPGPKeyAdd     Event = "pgp-key:add"
+	EventPGPKeyRemove  Event = "pgp-key:remove"
+	EventSSHKeyAdd     Event = "ssh-key:add"
+	EventSSHKeyRemove  Event = "ssh-key:remove"
+)
+
+// NewWebhook subscribes url to the provided events.
+//
+// API docs: https://man.sr.ht/api-conventions.md#webhooks
+func (c *Client) NewWebhook(ctx context.Context, url string, events ...Event) (Webhook, error) {
+	ctx = endpoints.Call(ctx, "user/webhooks")
+	hook := Webhook{}
+	j, err := json.Marshal(struct {
+		URL    string  `json:"url"`
+		Events []Event `json:"events"`
+	}{
+		URL:    url,
+		Events: events,
+	})
+	if err != nil {
+		return hook, err
+	}
+	_, err = c.do(ctx, "POST", "user/webhooks", "application/json", bytes.NewReader(j), &hook)
+	return hook, err
+}
+
+// GetWebhook returns the webhook subscription with the provided ID.
+func (c *Client) GetWebhook(ctx context.Context, id int64) (Webhook, error) {
+	ctx = endpoints.Call(ctx, "user/webhooks/{id}")
+	hook := Webhook{}
+	_, err := c.do(ctx, "GET", "user/webhooks/"+strconv.FormatInt(id, 10), "", nil, &hook)
+	return hook, err
+}
+
+// DeleteWebhook deletes the webhook subscription with the provided ID.
+func (c *Client) DeleteWebhook(ctx context.Context, id int64) error {
+	ctx = endpoints.Call(ctx, "user/webhooks/{id}")
+	_, err := c.do(ctx, "DELETE", "user/webhooks/"+strconv.FormatInt(id, 10), "", nil, nil)
+	return err
+}
+
+// ListWebhooks returns an iterator over the webhook subscriptions of the
+// authenticated user.
+func (c *Client) ListWebhooks(ctx context.Context) (WebhookIter, error) {
+	ctx = endpoints.Call(ctx, "user/webhooks")
+	return c.webhooks(ctx, "GET", "user/webhooks", nil)
+}
+
+func (c *Client) webhooks(ctx context.Context, method, u string, body io.Reader) (WebhookIter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return WebhookIter{}, err
+	}
+	u = c.baseURL.String() + u
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return WebhookIter{}, err
+	}
+	return WebhookIter{Iter: sourcehut.List[*Webhook](c.srhtClient, req)}, nil
+}
+
+// ErrInvalidSignature is returned by VerifyWebhook if a delivery was not
+// signed by the expected key.
+var ErrInvalidSignature = errors.New("meta: invalid webhook signature")
+
+// VerifyWebhook checks that the webhook payload was signed with the private
+// key matching key.
+// The signature and nonce are taken from the X-Payload-Signature and
+// X-Payload-Nonce headers of the delivery.
+//
+// To receive deliveries, use webhook.Handler with the meta events of package
+// webhook, such as webhook.MetaProfileUpdate, which verifies them and also
+// rejects replayed deliveries.
+func VerifyWebhook(key ed25519.PublicKey, payload []byte, signature, nonce string) error {
+	if !webhooksig.Verify(key, payload, signature, nonce) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// KeyRemoved is the payload of the pgp-key:remove and ssh-key:remove events.
+type KeyRemoved struct {
+	ID int64 `json:"id"`
+}
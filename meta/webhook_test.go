@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package meta_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+)
+
+var verifyWebhookTests = [...]struct {
+	payload string
+	nonce   string
+	sig     string
+	err     bool
+}{
+	0: {payload: `{"name": "jdoe"}`},
+	1: {payload: `{"name": "jdoe"}`, nonce: "other", err: true},
+	2: {payload: `{"name": "root"}`, err: true},
+	3: {payload: `{"name": "jdoe"}`, sig: "not base64", err: true},
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const nonce = "1234567890"
+	signed := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(`{"name": "jdoe"}`+nonce)))
+
+	for i, tc := range verifyWebhookTests {
+		n, sig := nonce, signed
+		if tc.nonce != "" {
+			n = tc.nonce
+		}
+		if tc.sig != "" {
+			sig = tc.sig
+		}
+		err := meta.VerifyWebhook(pub, []byte(tc.payload), sig, n)
+		switch {
+		case tc.err && !errors.Is(err, meta.ErrInvalidSignature):
+			t.Errorf("%d: expected invalid signature error, got: %v", i, err)
+		case !tc.err && err != nil:
+			t.Errorf("%d: unexpected error: %v", i, err)
+		}
+	}
+}
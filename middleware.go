@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Middleware intercepts the HTTP requests made by a client.
+// It is given the next http.RoundTripper in the chain and returns one that
+// wraps it, and may inspect or replace requests and responses along the way.
+//
+// Like any http.RoundTripper, the returned value must be safe for concurrent
+// use and must not modify the request it is given; clone it first if headers
+// need to be changed.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// Use returns an option that adds middleware to the client.
+//
+// Middleware runs for every HTTP request sent by the client, including each
+// retry, after the Authorization and User-Agent headers have been set and
+// after any rate limiting delay.
+// The first middleware is the outermost: Use(a, b) sends requests through a,
+// then b, then the RoundTripper configured with the RoundTripper option.
+// If Use is given multiple times, the middleware is appended in order.
+func Use(mw ...Middleware) Option {
+	return func(t *Transport) {
+		t.middleware = append(t.middleware, mw...)
+	}
+}
+
+// Intercept returns an option that adds middleware which runs once for each
+// request made with the client, before any rate limiting delay and outside of
+// retries.
+// Middleware added with Intercept sees the request after the Authorization and
+// User-Agent headers have been set, and sees only the final response if the
+// request is retried.
+//
+// It is meant for middleware that may answer requests without sending them,
+// such as a response cache, so that those responses do not wait for or use up
+// the rate limit.
+// Intercept(a, b) sends requests through a, then b, then the rate limiter,
+// retries, and any middleware added with Use.
+// If Intercept is given multiple times, the middleware is appended in order.
+func Intercept(mw ...Middleware) Option {
+	return func(t *Transport) {
+		t.intercept = append(t.intercept, mw...)
+	}
+}
+
+// chain wraps rt in the provided middleware.
+func chain(rt http.RoundTripper, mw []Middleware) http.RoundTripper {
+	for i := len(mw) - 1; i >= 0; i-- {
+		rt = mw[i](rt)
+	}
+	return rt
+}
+
+// redacted lists the headers whose values are replaced by Redact.
+var redacted = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// Redact returns a copy of h with the values of headers that contain
+// credentials, such as the Authorization header, replaced.
+// It is meant for logging headers.
+func Redact(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range redacted {
+		if _, ok := h[k]; ok {
+			h[k] = []string{"REDACTED"}
+		}
+	}
+	return h
+}
+
+// LogRequests returns middleware that logs every request and its response to l
+// at the debug level.
+// Headers are logged with credentials removed using Redact, and the service,
+// endpoint, and page are included for requests made by the service clients.
+//
+// Most users will want the Logger option instead, which logs retries as well.
+func LogRequests(l *slog.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.Redacted()),
+				slog.Any("request_header", Redact(req.Header)),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if info, ok := CallInfoFromContext(ctx); ok {
+				attrs = append(attrs,
+					slog.String("service", info.Service),
+					slog.String("endpoint", info.Endpoint),
+				)
+				if info.Page > 0 {
+					attrs = append(attrs, slog.Int("page", info.Page))
+				}
+			}
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+				l.LogAttrs(ctx, slog.LevelDebug, "request failed", attrs...)
+				return resp, err
+			}
+			attrs = append(attrs,
+				slog.Int("status", resp.StatusCode),
+				slog.Any("response_header", Redact(resp.Header)),
+			)
+			l.LogAttrs(ctx, slog.LevelDebug, "request", attrs...)
+			return resp, nil
+		})
+	}
+}
+
+// RequestIDHeader is the header set by the RequestID middleware.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestID returns middleware that sets the X-Request-Id header to a value
+// returned by gen on requests that do not already have one.
+// If gen is nil, a random 128-bit hex encoded ID is used.
+//
+// Because middleware runs once per attempt, retries of a request made with
+// this middleware are sent with different IDs; set the header on the request
+// itself to use the same ID for every attempt.
+func RequestID(gen func() string) Middleware {
+	if gen == nil {
+		gen = randomID
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(RequestIDHeader) == "" {
+				req = req.Clone(req.Context())
+				req.Header.Set(RequestIDHeader, gen())
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func randomID() string {
+	var b [16]byte
+	/* #nosec */
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Headers returns middleware that adds the provided headers to every request.
+// Values replace any existing values of the same header, except for the
+// Authorization and User-Agent headers which are always left unchanged.
+func Headers(h http.Header) Middleware {
+	add := make(http.Header, len(h))
+	for k, vs := range h {
+		for _, v := range vs {
+			add.Add(k, v)
+		}
+	}
+	add.Del("Authorization")
+	add.Del("User-Agent")
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for k, vs := range add {
+				req.Header[k] = append([]string(nil), vs...)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
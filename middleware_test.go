@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+		got   http.Header
+	)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, "server")
+		got = req.Header.Clone()
+		w.Write([]byte(`{}`))
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+
+	trace := func(name string) sourcehut.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				order = append(order, name)
+				if req.Header.Get("Authorization") == "" {
+					t.Errorf("Middleware %s ran before authorization", name)
+				}
+				mu.Unlock()
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	client := sourcehut.NewClient(
+		sourcehut.Token("token"),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+		sourcehut.Use(trace("a"), trace("b")),
+		sourcehut.Use(
+			sourcehut.RequestID(func() string { return "id" }),
+			sourcehut.Headers(http.Header{
+				"x-custom":      {"1", "2"},
+				"Authorization": {"override"},
+			}),
+			trace("c"),
+		),
+	)
+	req, err := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := strings.Join(order, ","); s != "a,b,c,server" {
+		t.Errorf("Unexpected middleware order: %s", s)
+	}
+	if v := got.Get(sourcehut.RequestIDHeader); v != "id" {
+		t.Errorf("Unexpected request ID: %q", v)
+	}
+	if v := got.Values("X-Custom"); len(v) != 2 || v[0] != "1" || v[1] != "2" {
+		t.Errorf("Unexpected custom header: %q", v)
+	}
+	if v := got.Get("Authorization"); v != "token token" {
+		t.Errorf("Headers middleware changed the Authorization header: %q", v)
+	}
+	if len(req.Header) != 0 {
+		t.Errorf("Middleware modified the request: %v", req.Header)
+	}
+}
+
+func TestLogRequestsRedacts(t *testing.T) {
+	const secret = "super-secret-token"
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Set-Cookie", "session="+secret)
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := sourcehut.NewClient(
+		sourcehut.Token(secret),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+		sourcehut.Use(sourcehut.LogRequests(logger)),
+	)
+	req, err := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/path", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(req, nil)
+	if err == nil {
+		t.Fatal("Expected error for unexpected status")
+	}
+
+	out := buf.String()
+	if strings.Contains(out, secret) {
+		t.Errorf("Log contains credentials: %s", out)
+	}
+	for _, want := range []string{"method=GET", "/path", "status=418", "Authorization:[REDACTED]", "User-Agent:[test]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Log does not contain %q: %s", want, out)
+		}
+	}
+}
+
+// roundTripperFunc is an adapter that allows the use of an ordinary function as
+// an http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestLoggerOption(t *testing.T) {
+	const secret = "super-secret-token"
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := sourcehut.NewClient(
+		sourcehut.Token(secret),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+		sourcehut.Retry(sourcehut.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+		sourcehut.Logger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+	ctx := sourcehut.WithCallInfo(context.Background(), sourcehut.CallInfo{Service: "git", Endpoint: "repos"})
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/repos", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got:\n%s", buf.String())
+	}
+	for i, want := range [][]string{
+		{"msg=request", "status=503", "service=git", "endpoint=repos"},
+		{`msg="retrying request"`, "attempt=1", "status=503"},
+		{"msg=request", "method=GET", "/repos", "status=200", "duration="},
+	} {
+		for _, w := range want {
+			if !strings.Contains(lines[i], w) {
+				t.Errorf("Log line %d does not contain %q: %s", i, w, lines[i])
+			}
+		}
+	}
+	if strings.Contains(buf.String(), secret) {
+		t.Errorf("Log contains credentials: %s", buf.String())
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Loopback listens on the loopback interface for the redirect at the end of
+// the authorization flow.
+// It allows command line tools to complete the flow without a web server:
+// set the config's RedirectURL to the URL of the Loopback, open the URL
+// returned by AuthCodeURL in the user's browser, and call Wait to receive the
+// code.
+//
+// API docs: https://www.rfc-editor.org/rfc/rfc8252#section-7.3
+type Loopback struct {
+	ln     net.Listener
+	path   string
+	result chan loopbackResult
+	done   chan struct{}
+	once   sync.Once
+	srv    *http.Server
+}
+
+var errNoState = errors.New("oauth: no state to check the redirect against")
+
+type loopbackResult struct {
+	state string
+	code  string
+	err   error
+
+	// accepted receives whether the redirect was used by Wait.
+	accepted chan bool
+}
+
+// ListenLoopback starts listening for a redirect on a random port of the IPv4
+// loopback address.
+// The redirect is expected on the provided path (eg. "/callback").
+func ListenLoopback(path string) (*Loopback, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	l := &Loopback{
+		ln:     ln,
+		path:   path,
+		result: make(chan loopbackResult),
+		done:   make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, l.handle)
+	l.srv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		/* #nosec */
+		_ = l.srv.Serve(ln)
+	}()
+	return l, nil
+}
+
+// RedirectURL returns the URL that the authorization server should redirect to.
+func (l *Loopback) RedirectURL() string {
+	return fmt.Sprintf("http://%s%s", l.ln.Addr(), l.path)
+}
+
+// Wait blocks until the redirect is received and returns the authorization
+// code, or the error reported by the authorization server.
+// The state must be the one passed to AuthCodeURL, which should be random
+// and not reused; redirects with a different state are rejected and Wait
+// keeps waiting.
+// If state is empty, Wait returns an error since any redirect would be
+// accepted.
+// Requests without a code or an error, such as those made when the browser
+// prefetches or reloads the page, are ignored.
+// The listener is closed when Wait returns.
+func (l *Loopback) Wait(ctx context.Context, state string) (string, error) {
+	defer l.shutdown()
+
+	if state == "" {
+		return "", errNoState
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case r := <-l.result:
+			if r.state != state {
+				r.accepted <- false
+				continue
+			}
+			r.accepted <- true
+			if r.err != nil {
+				return "", r.err
+			}
+			return r.code, nil
+		}
+	}
+}
+
+// Close stops listening for the redirect.
+func (l *Loopback) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return l.srv.Close()
+}
+
+// shutdown stops listening for the redirect after the response to the redirect
+// has been sent.
+func (l *Loopback) shutdown() {
+	l.once.Do(func() { close(l.done) })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	/* #nosec */
+	_ = l.srv.Shutdown(ctx)
+}
+
+func (l *Loopback) handle(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	r := loopbackResult{
+		state:    q.Get("state"),
+		code:     q.Get("code"),
+		accepted: make(chan bool, 1),
+	}
+	if e := q.Get("error"); e != "" {
+		r.err = Error{Code: e, Description: q.Get("error_description"), URI: q.Get("error_uri")}
+	} else if r.code == "" {
+		http.Error(w, "No authorization code in request.", http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case l.result <- r:
+	case <-l.done:
+		http.Error(w, "Authorization already completed.", http.StatusConflict)
+		return
+	case <-req.Context().Done():
+		return
+	}
+	if !<-r.accepted {
+		http.Error(w, "State in request does not match.", http.StatusBadRequest)
+		return
+	}
+	if r.err != nil {
+		http.Error(w, "Authorization failed, you may close this window.", http.StatusBadRequest)
+		return
+	}
+	/* #nosec */
+	_, _ = fmt.Fprintln(w, "Authorization complete, you may close this window.")
+}
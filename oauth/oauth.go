@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package oauth implements the OAuth 2.0 authorization code grant used by
+// meta.sr.ht to authorize third party applications.
+//
+// A typical flow redirects the user to the URL returned by AuthCodeURL,
+// exchanges the code that is passed back to the redirect URL for a Token, and
+// then configures a sourcehut.Client with a TokenSource that keeps the token
+// fresh:
+//
+//	tok, err := conf.Exchange(ctx, code)
+//	…
+//	srhtClient := sourcehut.NewClient(
+//		sourcehut.TokenSource(conf.TokenSource(tok)),
+//		sourcehut.UserAgent("my-app"),
+//	)
+//
+// API docs: https://man.sr.ht/meta.sr.ht/oauth.md
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BaseURL is the default public meta.sr.ht URL.
+// It is exported for convenience.
+const BaseURL = "https://meta.sr.ht/"
+
+// expiryDelta is how long before its expiry a token is considered expired so
+// that requests started just before the expiry don't fail.
+const expiryDelta = 10 * time.Second
+
+var errNoRefreshToken = errors.New("token expired and no refresh token is available")
+
+// Config describes an OAuth 2.0 client registered on meta.sr.ht.
+type Config struct {
+	// ClientID and ClientSecret are the credentials of the registered client.
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the URL the user is sent back to after authorizing the
+	// client.
+	// It must match the redirect URL used when the client was registered.
+	RedirectURL string
+
+	// Scopes is the list of grants being requested (eg. "meta.sr.ht/PROFILE:RO").
+	// If empty, the grants configured on the client are used.
+	Scopes []string
+
+	// Base is the URL of the meta.sr.ht instance.
+	// If empty, BaseURL is used.
+	Base string
+
+	// HTTPClient is used to make requests to the token endpoints.
+	// If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// Token is an OAuth 2.0 access token and the information needed to refresh it.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	Scopes       []string  `json:"scopes,omitempty"`
+}
+
+// Valid reports whether the token is set and not about to expire.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// Error is an error response returned by the OAuth 2.0 token endpoints.
+//
+// API docs: https://www.rfc-editor.org/rfc/rfc6749#section-5.2
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+	URI         string `json:"error_uri"`
+
+	statusCode int
+}
+
+// StatusCode returns the HTTP status code of the response that contained the
+// error.
+func (err Error) StatusCode() int {
+	return err.statusCode
+}
+
+// Error satisfies the error interface for Error.
+func (err Error) Error() string {
+	if err.Description == "" {
+		return err.Code
+	}
+	return err.Code + ": " + err.Description
+}
+
+func (c *Config) endpoint(p string) string {
+	base := c.Base
+	if base == "" {
+		base = BaseURL
+	}
+	return strings.TrimSuffix(base, "/") + "/oauth2/" + p
+}
+
+func (c *Config) client() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// AuthCodeURL returns the URL that the user should be sent to in order to
+// authorize the client.
+// The state is passed back to the redirect URL unchanged and should be checked
+// to prevent cross site request forgery.
+func (c *Config) AuthCodeURL(state string) string {
+	v := url.Values{
+		"response_type": {"code"},
+		"client_id":     {c.ClientID},
+	}
+	if c.RedirectURL != "" {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+	if len(c.Scopes) > 0 {
+		v.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	if state != "" {
+		v.Set("state", state)
+	}
+	return c.endpoint("authorize") + "?" + v.Encode()
+}
+
+// Exchange trades the authorization code passed to the redirect URL for a
+// token.
+func (c *Config) Exchange(ctx context.Context, code string) (*Token, error) {
+	v := url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {code},
+	}
+	if c.RedirectURL != "" {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+	return c.token(ctx, v)
+}
+
+// Refresh uses the refresh token to obtain a new access token.
+func (c *Config) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (c *Config) token(ctx context.Context, v url.Values) (*Token, error) {
+	resp, err := c.post(ctx, "access-token", v)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var t struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		Scope        string `json:"scope"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&t)
+	if err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, errors.New("no access token in response")
+	}
+	tok := &Token{
+		AccessToken:  t.AccessToken,
+		TokenType:    t.TokenType,
+		RefreshToken: t.RefreshToken,
+		Scopes:       strings.Fields(t.Scope),
+	}
+	if t.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	// The refresh token is not always rotated, if it wasn't keep using the old
+	// one.
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = v.Get("refresh_token")
+	}
+	return tok, nil
+}
+
+func (c *Config) post(ctx context.Context, p string, v url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(p), strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return nil, err
+		}
+		e := Error{statusCode: resp.StatusCode}
+		if err = json.Unmarshal(body, &e); err != nil || e.Code == "" {
+			return nil, fmt.Errorf("unexpected response status %d from token endpoint: %s", resp.StatusCode, body)
+		}
+		return nil, e
+	}
+	return resp, nil
+}
+
+// TokenSource returns a TokenSource that returns t until it expires and then
+// refreshes it automatically.
+func (c *Config) TokenSource(t *Token) *TokenSource {
+	return &TokenSource{conf: c, t: t}
+}
+
+// TokenSource provides OAuth 2.0 tokens, refreshing them when they expire.
+// It implements sourcehut.AccessTokenSource and can be passed to the
+// sourcehut.TokenSource option.
+//
+// TokenSource is safe for concurrent use.
+type TokenSource struct {
+	// OnRefresh, if set, is called with the new token every time a token is
+	// refreshed so that it can be persisted.
+	// It must be set before the TokenSource is first used.
+	OnRefresh func(*Token)
+
+	conf *Config
+	mu   sync.Mutex
+	t    *Token
+}
+
+// Token returns the current token, refreshing it first if it has expired.
+func (s *TokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.t.Valid() {
+		return s.t, nil
+	}
+	if s.t == nil || s.t.RefreshToken == "" {
+		return nil, errNoRefreshToken
+	}
+	t, err := s.conf.Refresh(ctx, s.t.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	s.t = t
+	if s.OnRefresh != nil {
+		s.OnRefresh(t)
+	}
+	return t, nil
+}
+
+// AccessToken returns the current access token, refreshing it first if it has
+// expired.
+func (s *TokenSource) AccessToken(ctx context.Context) (string, error) {
+	t, err := s.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return t.AccessToken, nil
+}
@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package oauth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+	"git.sr.ht/~wombelix/sourcehut-go/oauth"
+)
+
+// metaServer is a stand-in for the meta.sr.ht OAuth 2.0 endpoints.
+type metaServer struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	refreshes int
+}
+
+func (m *metaServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if req.URL.Path == "/api/user/profile" {
+		if auth := req.Header.Get("Authorization"); auth != fmt.Sprintf("Bearer access-%d", m.refreshes) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors": [{"reason": "Invalid token"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"name": "user"}`)
+		return
+	}
+
+	id, secret, ok := req.BasicAuth()
+	if !ok || id != "client" || secret != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": "invalid_client"}`)
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		m.t.Errorf("Error parsing form: %v", err)
+	}
+	switch req.URL.Path {
+	case "/oauth2/access-token":
+		switch req.PostForm.Get("grant_type") {
+		case "authorization_code":
+			if req.PostForm.Get("code") != "code" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "invalid_grant", "error_description": "Invalid code"}`)
+				return
+			}
+			// Already expired so that the first use triggers a refresh.
+			fmt.Fprint(w, `{"access_token": "access-0", "token_type": "bearer", "expires_in": 1, "refresh_token": "refresh", "scope": "meta.sr.ht/PROFILE:RO"}`)
+		case "refresh_token":
+			if req.PostForm.Get("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "invalid_grant"}`)
+				return
+			}
+			m.refreshes++
+			fmt.Fprintf(w, `{"access_token": "access-%d", "token_type": "bearer", "expires_in": 3600}`, m.refreshes)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newConfig(t *testing.T) (*oauth.Config, *metaServer, *httptest.Server) {
+	t.Helper()
+	m := &metaServer{t: t}
+	server := httptest.NewUnstartedServer(m)
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+	return &oauth.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://127.0.0.1/callback",
+		Scopes:       []string{"meta.sr.ht/PROFILE:RO"},
+		Base:         server.URL,
+		HTTPClient:   server.Client(),
+	}, m, server
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	conf := &oauth.Config{
+		ClientID:    "client",
+		RedirectURL: "http://127.0.0.1/callback",
+		Scopes:      []string{"meta.sr.ht/PROFILE:RO", "git.sr.ht/REPOSITORIES:RW"},
+	}
+	u, err := url.Parse(conf.AuthCodeURL("state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "meta.sr.ht" || u.Path != "/oauth2/authorize" {
+		t.Errorf("Unexpected authorization endpoint: %s", u)
+	}
+	want := url.Values{
+		"response_type": {"code"},
+		"client_id":     {"client"},
+		"redirect_uri":  {"http://127.0.0.1/callback"},
+		"scope":         {"meta.sr.ht/PROFILE:RO git.sr.ht/REPOSITORIES:RW"},
+		"state":         {"state"},
+	}
+	if q := u.Query(); q.Encode() != want.Encode() {
+		t.Errorf("Unexpected query: want=%q, got=%q", want.Encode(), q.Encode())
+	}
+}
+
+func TestExchangeError(t *testing.T) {
+	conf, _, _ := newConfig(t)
+	_, err := conf.Exchange(context.Background(), "bad")
+	var oauthErr oauth.Error
+	if !errors.As(err, &oauthErr) {
+		t.Fatalf("Unexpected error type %T: %v", err, err)
+	}
+	if oauthErr.Code != "invalid_grant" || oauthErr.Description != "Invalid code" || oauthErr.StatusCode() != 400 {
+		t.Errorf("Unexpected error: %#v", oauthErr)
+	}
+}
+
+func TestTokenSourceRefresh(t *testing.T) {
+	conf, m, server := newConfig(t)
+	ctx := context.Background()
+
+	tok, err := conf.Exchange(ctx, "code")
+	if err != nil {
+		t.Fatalf("Error exchanging code: %v", err)
+	}
+	if tok.RefreshToken != "refresh" || len(tok.Scopes) != 1 || tok.Valid() {
+		t.Fatalf("Unexpected token: %+v", tok)
+	}
+
+	var refreshed []*oauth.Token
+	ts := conf.TokenSource(tok)
+	ts.OnRefresh = func(t *oauth.Token) {
+		refreshed = append(refreshed, t)
+	}
+	client := sourcehut.NewClient(
+		sourcehut.TokenSource(ts),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+	)
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/user/profile", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var user sourcehut.User
+		_, err = client.Do(req, &user)
+		if err != nil {
+			t.Fatalf("Error on request %d: %v", i, err)
+		}
+	}
+	if m.refreshes != 1 || len(refreshed) != 1 {
+		t.Fatalf("Expected exactly one refresh, got %d (%d callbacks)", m.refreshes, len(refreshed))
+	}
+	if refreshed[0].RefreshToken != "refresh" {
+		t.Errorf("Refresh token was not preserved: %+v", refreshed[0])
+	}
+}
+
+func TestLoopback(t *testing.T) {
+	for i, tc := range [...]struct {
+		queries []string
+		state   string
+		code    string
+		fail    bool
+	}{
+		0: {queries: []string{"code=abc&state=xyz"}, state: "xyz", code: "abc"},
+		1: {queries: []string{"", "state=xyz", "code=abc&state=xyz"}, state: "xyz", code: "abc"},
+		2: {queries: []string{"code=bad&state=other", "error=access_denied&state=other", "code=abc&state=xyz"}, state: "xyz", code: "abc"},
+		3: {queries: []string{"code=abc"}, fail: true},
+		4: {queries: []string{"error=access_denied&state=xyz"}, state: "xyz", fail: true},
+		5: {queries: []string{"error=access_denied", "error=access_denied&state=xyz"}, state: "xyz", fail: true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			l, err := oauth.ListenLoopback("callback")
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := url.Parse(l.RedirectURL())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Hostname() != "127.0.0.1" || u.Path != "/callback" {
+				t.Fatalf("Unexpected redirect URL: %s", u)
+			}
+
+			// Every request but the last should be ignored by Wait.
+			ignored := make(chan int, len(tc.queries))
+			go func() {
+				defer close(ignored)
+				for _, q := range tc.queries[:len(tc.queries)-1] {
+					resp, err := http.Get(l.RedirectURL() + "?" + q)
+					if err != nil {
+						ignored <- 0
+						continue
+					}
+					resp.Body.Close()
+					ignored <- resp.StatusCode
+				}
+				resp, err := http.Get(l.RedirectURL() + "?" + tc.queries[len(tc.queries)-1])
+				if err == nil {
+					resp.Body.Close()
+				}
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			code, err := l.Wait(ctx, tc.state)
+			switch {
+			case tc.fail && err == nil:
+				t.Fatalf("Expected error, got code %q", code)
+			case !tc.fail && err != nil:
+				t.Fatalf("Unexpected error: %v", err)
+			case code != tc.code:
+				t.Fatalf("Unexpected code: want=%q, got=%q", tc.code, code)
+			}
+			for status := range ignored {
+				if status != http.StatusBadRequest {
+					t.Errorf("Unexpected status for ignored request: want=%d, got=%d", http.StatusBadRequest, status)
+				}
+			}
+		})
+	}
+}
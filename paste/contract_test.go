@@ -0,0 +1,20 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package paste_test
+
+import (
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/contract"
+	"git.sr.ht/~wombelix/sourcehut-go/paste"
+)
+
+func TestContract(t *testing.T) {
+	contract.Check(t, "testdata/contract.json", map[string]func() interface{}{
+		"Paste": func() interface{} { return &paste.Paste{} },
+		"Files": func() interface{} { return &paste.Files{} },
+		"Blob":  func() interface{} { return &paste.Blob{} },
+	})
+}
@@ -10,10 +10,10 @@ import (
 
 // Iter is used for iterating over a collection of pastes.
 type Iter struct {
-	*sourcehut.Iter
+	*sourcehut.Iter[*Paste]
 }
 
 // Paste returns the paste which the iterator is currently pointing to.
 func (i Iter) Paste() *Paste {
-	return i.Current().(*Paste)
+	return i.Current()
 }
@@ -7,6 +7,7 @@ package paste
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -14,12 +15,24 @@ import (
 	"strings"
 
 	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/jsonstream"
 )
 
 // BaseURL is the default public Sourcehut paste API URL.
 // It is exported for convenience.
 const BaseURL = "https://paste.sr.ht/api/"
 
+// endpoints identifies the calls made by the client and lists the endpoints
+// that anonymous clients can read.
+var endpoints = sourcehut.Endpoints{
+	Service: sourcehut.ServicePaste,
+	Public: map[string]bool{
+		"version":      true,
+		"pastes/{sha}": true,
+		"blobs/{sha}":  true,
+	},
+}
+
 // Option is used to configure an API client.
 type Option func(*Client) error
 
@@ -55,6 +68,17 @@ func Base(base string) Option {
 	}
 }
 
+// Instance returns an option that configures the client to use the paste API
+// of the provided Sourcehut instance and the sourcehut.Client that the instance
+// was created with.
+// Options that follow it, such as Base, override its settings.
+func Instance(inst *sourcehut.Instance) Option {
+	return func(c *Client) error {
+		c.srhtClient = inst.Client()
+		return Base(inst.BaseURL(endpoints.Service))(c)
+	}
+}
+
 // Client handles communication with the paste related methods of the Sourcehut
 // API.
 //
@@ -90,28 +114,32 @@ func NewClient(opts ...Option) (*Client, error) {
 // Version returns the version of the API.
 //
 // API docs: https://man.sr.ht/api-conventions.md#get-apiversion
-func (c *Client) Version() (string, error) {
+func (c *Client) Version(ctx context.Context) (string, error) {
+	ctx = endpoints.Call(ctx, "version")
 	var ver struct {
 		Version string `json:"version"`
 	}
-	_, err := c.do("GET", "version", "", nil, &ver)
+	_, err := c.do(ctx, "GET", "version", "", nil, &ver)
 	return ver.Version, err
 }
 
 // List returns an iterator over all pastes owned by the authenticated user.
-func (c *Client) List() (Iter, error) {
-	return c.list("GET", "pastes", nil)
+func (c *Client) List(ctx context.Context) (Iter, error) {
+	ctx = endpoints.Call(ctx, "pastes")
+	return c.list(ctx, "GET", "pastes", nil)
 }
 
 // Get returns information about a paste with the given ID.
-func (c *Client) Get(id string) (Paste, error) {
+func (c *Client) Get(ctx context.Context, id string) (Paste, error) {
+	ctx = endpoints.Call(ctx, "pastes/{sha}")
 	p := Paste{}
-	_, err := c.do("GET", "pastes/"+url.PathEscape(id), "", nil, &p)
+	_, err := c.do(ctx, "GET", "pastes/"+url.PathEscape(id), "", nil, &p)
 	return p, err
 }
 
 // New creates an new paste from the list of files.
-func (c *Client) New(f Files) (Paste, error) {
+func (c *Client) New(ctx context.Context, f Files) (Paste, error) {
+	ctx = endpoints.Call(ctx, "pastes")
 	p := Paste{}
 	buf := &bytes.Buffer{}
 	e := json.NewEncoder(buf)
@@ -123,20 +151,55 @@ func (c *Client) New(f Files) (Paste, error) {
 	if err != nil {
 		return Paste{}, err
 	}
-	_, err = c.do("POST", "pastes", "application/json", buf, &p)
+	_, err = c.do(ctx, "POST", "pastes", "application/json", buf, &p)
 	return p, err
 }
 
 // GetBlob returns information about a particular file in a paste.
-func (c *Client) GetBlob(id string) (Blob, error) {
+func (c *Client) GetBlob(ctx context.Context, id string) (Blob, error) {
+	ctx = endpoints.Call(ctx, "blobs/{sha}")
 	p := Blob{}
-	_, err := c.do("GET", "blobs/"+url.PathEscape(id), "", nil, &p)
+	_, err := c.do(ctx, "GET", "blobs/"+url.PathEscape(id), "", nil, &p)
 	return p, err
 }
 
-func (c *Client) do(method, u, contentType string, body io.Reader, v interface{}) (*http.Response, error) {
+// BlobContents returns the contents of a file in a paste.
+// Unlike GetBlob, the contents are decoded while they are being read from the
+// response instead of being loaded into memory first, which is preferable for
+// large files.
+//
+// The caller must close the returned reader.
+func (c *Client) BlobContents(ctx context.Context, id string) (io.ReadCloser, error) {
+	ctx = endpoints.Call(ctx, "blobs/{sha}")
+	if err := endpoints.Authorize(ctx, c.srhtClient, "GET"); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL.String()+"blobs/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.srhtClient.Send(req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := jsonstream.StringField(resp.Body, "contents")
+	if err != nil {
+		/* #nosec */
+		resp.Body.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{Reader: r, Closer: resp.Body}, nil
+}
+
+func (c *Client) do(ctx context.Context, method, u, contentType string, body io.Reader, v interface{}) (*http.Response, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return nil, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return nil, err
 	}
@@ -146,14 +209,14 @@ func (c *Client) do(method, u, contentType string, body io.Reader, v interface{}
 	return c.srhtClient.Do(req, v)
 }
 
-func (c *Client) list(method, u string, body io.Reader) (Iter, error) {
+func (c *Client) list(ctx context.Context, method, u string, body io.Reader) (Iter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return Iter{}, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return Iter{}, err
 	}
-	iter := c.srhtClient.List(req, func() interface{} {
-		return &Paste{}
-	})
-	return Iter{Iter: iter}, nil
+	return Iter{Iter: sourcehut.List[*Paste](c.srhtClient, req)}, nil
 }
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package paste_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/paste"
+	"git.sr.ht/~wombelix/sourcehut-go/srhttest"
+)
+
+func newClient(tb testing.TB) (*paste.Client, *srhttest.Server) {
+	tb.Helper()
+	srv := srhttest.NewServer()
+	tb.Cleanup(srv.Close)
+	client, err := paste.NewClient(paste.Instance(srv.Instance()))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return client, srv
+}
+
+func TestBlobContents(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	const contents = "package main\n\nfunc main() {\n\tprintln(\"héllo, \\\"world\\\" ☃\")\n}\n"
+	p, err := client.New(ctx, paste.Files{{Name: "main.go", Contents: contents}})
+	if err != nil {
+		t.Fatalf("Error creating paste: %v", err)
+	}
+	r, err := client.BlobContents(ctx, p.Files[0].ID)
+	if err != nil {
+		t.Fatalf("Error opening blob: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Error reading blob: %v", err)
+	}
+	if err = r.Close(); err != nil {
+		t.Errorf("Error closing blob: %v", err)
+	}
+	if string(got) != contents {
+		t.Errorf("Wrong contents: want=%q, got=%q", contents, got)
+	}
+
+	_, err = client.BlobContents(ctx, "missing")
+	if !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+}
+
+// largeBlob creates a paste with a 1MiB file and returns the ID of its blob.
+func largeBlob(b *testing.B) (*paste.Client, string) {
+	client, _ := newClient(b)
+	contents := strings.Repeat("All work and no play makes Jack a dull boy.\n", 1<<20/44)
+	p, err := client.New(context.Background(), paste.Files{{Name: "large.txt", Contents: contents}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(contents)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	return client, p.Files[0].ID
+}
+
+func BenchmarkGetBlob(b *testing.B) {
+	client, id := largeBlob(b)
+	ctx := context.Background()
+	for n := 0; n < b.N; n++ {
+		blob, err := client.GetBlob(ctx, id)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = io.WriteString(io.Discard, blob.Contents)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBlobContents(b *testing.B) {
+	client, id := largeBlob(b)
+	ctx := context.Background()
+	for n := 0; n < b.N; n++ {
+		r, err := client.BlobContents(ctx, id)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = io.Copy(io.Discard, r)
+		if err != nil {
+			b.Fatal(err)
+		}
+		/* #nosec */
+		r.Close()
+	}
+}
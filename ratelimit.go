@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errLimitExceeded is returned by the Transport when a request would exceed the
+// configured rate limit and the RateLimitPolicy is set to fail fast.
+// It matches ErrRateLimited.
+var errLimitExceeded error = limitExceededError{}
+
+type limitExceededError struct{}
+
+func (limitExceededError) Error() string {
+	return "rate limit exceeded"
+}
+
+func (limitExceededError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimitPolicy configures a token bucket that limits how quickly requests
+// are sent to the API.
+//
+// Regardless of the configured rate, the limiter also adapts to the rate limit
+// reported by the server: if a response indicates that no requests remain
+// (using the X-RateLimit-Remaining and X-RateLimit-Reset headers) or includes a
+// Retry-After header, further requests are held back until the server is ready
+// to accept them again.
+type RateLimitPolicy struct {
+	// Rate is the number of requests per second that may be sent on average.
+	// If zero, only limits reported by the server are enforced.
+	Rate float64
+
+	// Burst is the maximum number of requests that may be sent at once.
+	// Values less than 1 are treated as 1.
+	Burst int
+
+	// FailFast causes requests that would exceed the limit to fail immediately
+	// with an error matching ErrRateLimited instead of waiting.
+	FailFast bool
+}
+
+// RateLimit returns an option that configures the client to limit the rate at
+// which requests are sent.
+// Because the limit is enforced by the Transport, it is shared between all
+// service clients that use the same sourcehut.Client.
+// If unspecified, requests are not rate limited.
+func RateLimit(p RateLimitPolicy) Option {
+	return func(rt *Transport) {
+		rt.limiter = newRateLimiter(p)
+	}
+}
+
+type rateLimiter struct {
+	policy RateLimitPolicy
+
+	mu        sync.Mutex
+	tokens    float64
+	last      time.Time
+	notBefore time.Time
+}
+
+func newRateLimiter(p RateLimitPolicy) *rateLimiter {
+	if p.Burst < 1 {
+		p.Burst = 1
+	}
+	return &rateLimiter{
+		policy: p,
+		tokens: float64(p.Burst),
+	}
+}
+
+// reserve takes a token if one is available, otherwise it returns how long the
+// caller must wait before trying again.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.notBefore) {
+		return l.notBefore.Sub(now)
+	}
+	if l.policy.Rate <= 0 {
+		return 0
+	}
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.policy.Rate
+		if burst := float64(l.policy.Burst); l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.policy.Rate * float64(time.Second))
+}
+
+// wait blocks until a request may be sent or the context is canceled.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		d := l.reserve(time.Now())
+		if d <= 0 {
+			return nil
+		}
+		if l.policy.FailFast {
+			return errLimitExceeded
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// observe adjusts the limiter based on the rate limit reported in resp.
+func (l *rateLimiter) observe(resp *http.Response) {
+	now := time.Now()
+	var until time.Time
+	if d, ok := retryAfter(resp, now); ok && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		until = now.Add(d)
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil && remaining <= 0 {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if t := time.Unix(reset, 0); t.After(until) {
+				until = t
+			}
+		}
+	}
+	if until.IsZero() {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.notBefore) {
+		l.notBefore = until
+	}
+}
+
+// roundTrip waits for the limiter before sending req using rt.
+func (l *rateLimiter) roundTrip(rt http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if l == nil {
+		return rt.RoundTrip(req)
+	}
+	if err := l.wait(req.Context()); err != nil {
+		// The request is never sent, but RoundTrip must still close the body.
+		if req.Body != nil {
+			/* #nosec */
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := rt.RoundTrip(req)
+	if err == nil {
+		l.observe(resp)
+	}
+	return resp, err
+}
@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+)
+
+func newRateLimitServer(t *testing.T, h http.HandlerFunc) (*httptest.Server, func(sourcehut.RateLimitPolicy) *sourcehut.Transport) {
+	t.Helper()
+	server := httptest.NewUnstartedServer(h)
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, func(p sourcehut.RateLimitPolicy) *sourcehut.Transport {
+		return sourcehut.NewTransport(
+			sourcehut.Token("token"),
+			sourcehut.UserAgent("test"),
+			sourcehut.RoundTripper(server.Client().Transport),
+			sourcehut.RateLimit(p),
+		)
+	}
+}
+
+func roundTrip(t *testing.T, rt http.RoundTripper, u string) error {
+	t.Helper()
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestRateLimitFailFast(t *testing.T) {
+	server, newTransport := newRateLimitServer(t, func(http.ResponseWriter, *http.Request) {})
+	rt := newTransport(sourcehut.RateLimitPolicy{Rate: 0.001, Burst: 2, FailFast: true})
+
+	for i := 0; i < 2; i++ {
+		if err := roundTrip(t, rt, server.URL); err != nil {
+			t.Fatalf("Unexpected error on request %d within burst: %v", i, err)
+		}
+	}
+	if err := roundTrip(t, rt, server.URL); !errors.Is(err, sourcehut.ErrRateLimited) {
+		t.Fatalf("Unexpected error: want=%v, got=%v", sourcehut.ErrRateLimited, err)
+	}
+}
+
+func TestRateLimitBlocks(t *testing.T) {
+	server, newTransport := newRateLimitServer(t, func(http.ResponseWriter, *http.Request) {})
+	rt := newTransport(sourcehut.RateLimitPolicy{Rate: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := roundTrip(t, rt, server.URL); err != nil {
+			t.Fatalf("Unexpected error on request %d: %v", i, err)
+		}
+	}
+	// The first request uses the burst, the next two wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("Requests were not rate limited: took %v", elapsed)
+	}
+}
+
+func TestRateLimitServerHeaders(t *testing.T) {
+	var served int
+	server, newTransport := newRateLimitServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		served++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	})
+	rt := newTransport(sourcehut.RateLimitPolicy{FailFast: true})
+
+	if err := roundTrip(t, rt, server.URL); err != nil {
+		t.Fatalf("Unexpected error on first request: %v", err)
+	}
+	if err := roundTrip(t, rt, server.URL); !sourcehut.IsRateLimited(err) {
+		t.Fatalf("Unexpected error after server limit was exhausted: want=%v, got=%v", sourcehut.ErrRateLimited, err)
+	}
+	if served != 1 {
+		t.Fatalf("Unexpected number of requests reached the server: want=1, got=%d", served)
+	}
+}
+
+// closeRecorder is a request body that records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRateLimitClosesBody(t *testing.T) {
+	server, newTransport := newRateLimitServer(t, func(http.ResponseWriter, *http.Request) {})
+	rt := newTransport(sourcehut.RateLimitPolicy{Rate: 0.001, Burst: 1, FailFast: true})
+
+	if err := roundTrip(t, rt, server.URL); err != nil {
+		t.Fatalf("Unexpected error on first request: %v", err)
+	}
+	body := &closeRecorder{Reader: strings.NewReader("body")}
+	req, err := http.NewRequest("POST", server.URL, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = rt.RoundTrip(req)
+	if !errors.Is(err, sourcehut.ErrRateLimited) {
+		t.Fatalf("Unexpected error: want=%v, got=%v", sourcehut.ErrRateLimited, err)
+	}
+	if !body.closed {
+		t.Fatal("Request body was not closed after the limiter failed")
+	}
+}
+
+func TestRateLimitCanceledClosesBody(t *testing.T) {
+	server, newTransport := newRateLimitServer(t, func(http.ResponseWriter, *http.Request) {})
+	rt := newTransport(sourcehut.RateLimitPolicy{Rate: 0.001, Burst: 1})
+
+	if err := roundTrip(t, rt, server.URL); err != nil {
+		t.Fatalf("Unexpected error on first request: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body := &closeRecorder{Reader: strings.NewReader("body")}
+	req, err := http.NewRequestWithContext(ctx, "POST", server.URL, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = rt.RoundTrip(req)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Unexpected error: want=%v, got=%v", context.Canceled, err)
+	}
+	if !body.closed {
+		t.Fatal("Request body was not closed after the context was canceled")
+	}
+}
@@ -0,0 +1,262 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package replay provides an http.RoundTripper that records API exchanges to a
+// cassette file and replays them later without network access.
+//
+// A Recorder is meant to be used as the base transport of a sourcehut.Client:
+//
+//	rec, err := replay.New("testdata/repos.json", replay.ModeReplay, nil)
+//	if err != nil {
+//		…
+//	}
+//	srhtClient := sourcehut.NewClient(
+//		sourcehut.Token(token),
+//		sourcehut.UserAgent("test"),
+//		sourcehut.RoundTripper(rec),
+//	)
+//
+// The Authorization header that is set by the client is never written to the
+// cassette, so cassettes recorded with a real access token can be committed.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNoMatch is returned by a replaying Recorder when a request does not match
+// any of the remaining interactions in the cassette.
+var ErrNoMatch = errors.New("replay: no matching interaction")
+
+// Mode controls whether a Recorder records or replays interactions.
+type Mode int
+
+// Valid modes.
+const (
+	// ModeReplay serves responses from an existing cassette and never touches
+	// the network.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests to the underlying transport and records the
+	// exchanges so that they can be written to the cassette.
+	ModeRecord
+)
+
+// scrubbed lists the request headers that are never written to a cassette.
+var scrubbed = []string{"Authorization", "Cookie"}
+
+// Cassette is the on-disk format of a set of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single request and the response that it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+// URL only contains the path and query of the original request so that a
+// cassette can be replayed against any host.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records or replays interactions.
+// It is safe for concurrent use.
+type Recorder struct {
+	path string
+	mode Mode
+	rt   http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a Recorder backed by the cassette at path.
+//
+// In ModeReplay the cassette is loaded immediately and requests are matched
+// against it strictly on method, path, query, and body; each interaction is
+// used at most once and in the order in which it was recorded.
+// In ModeRecord requests are sent using rt (or http.DefaultTransport if rt is
+// nil) and the cassette is written when Close is called.
+func New(path string, mode Mode, rt http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{
+		path: path,
+		mode: mode,
+		rt:   rt,
+	}
+	if r.rt == nil {
+		r.rt = http.DefaultTransport
+	}
+	if mode != ModeReplay {
+		return r, nil
+	}
+
+	/* #nosec */
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	/* #nosec */
+	defer f.Close()
+	err = json.NewDecoder(f).Decode(&r.cassette)
+	if err != nil {
+		return nil, fmt.Errorf("replay: error decoding cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		/* #nosec */
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.mode == ModeRecord {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = http.NoBody
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.rt.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	/* #nosec */
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := req.Header.Clone()
+	for _, k := range scrubbed {
+		header.Del(k)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: header,
+			Body:   string(body),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	})
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || !matches(in.Request, req, body) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w for %s %s", ErrNoMatch, req.Method, req.URL.RequestURI())
+}
+
+// matches reports whether req is the recorded request rec.
+func matches(rec Request, req *http.Request, body []byte) bool {
+	if rec.Method != req.Method || rec.Body != string(body) {
+		return false
+	}
+	u, err := url.Parse(rec.URL)
+	if err != nil {
+		return false
+	}
+	return u.EscapedPath() == req.URL.EscapedPath() &&
+		u.Query().Encode() == req.URL.Query().Encode()
+}
+
+// Close finishes the recording or replay.
+//
+// In ModeRecord the recorded interactions are written to the cassette,
+// replacing any existing file.
+// In ModeReplay an error is returned if any interactions in the cassette were
+// never requested, which usually means that the code under test changed.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mode == ModeReplay {
+		var unused []string
+		for i, in := range r.cassette.Interactions {
+			if !r.used[i] {
+				unused = append(unused, in.Request.Method+" "+in.Request.URL)
+			}
+		}
+		if len(unused) > 0 {
+			return fmt.Errorf("replay: %d unused interactions in %s: %s", len(unused), r.path, strings.Join(unused, ", "))
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	err := enc.Encode(r.cassette)
+	if err != nil {
+		return err
+	}
+	/* #nosec */
+	return os.WriteFile(r.path, buf.Bytes(), 0o644)
+}
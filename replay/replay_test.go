@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package replay_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+	"git.sr.ht/~wombelix/sourcehut-go/replay"
+)
+
+const secret = "super-secret-token"
+
+func newClient(rt http.RoundTripper) sourcehut.Client {
+	return sourcehut.NewClient(
+		sourcehut.Token(secret),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(rt),
+	)
+}
+
+func post(t *testing.T, c sourcehut.Client, base, path, body string) (string, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), "POST", base+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Echo string `json:"echo"`
+	}
+	_, err = c.Do(req, &v)
+	return v.Echo, err
+}
+
+func TestRecordReplay(t *testing.T) {
+	var served int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		served++
+		if req.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": [{"reason": "Not found"}]}`)
+			return
+		}
+		b, _ := io.ReadAll(req.Body)
+		fmt.Fprintf(w, `{"echo": %q}`, fmt.Sprintf("%s?%s %s", req.URL.Path, req.URL.RawQuery, b))
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := replay.New(cassette, replay.ModeRecord, server.Client().Transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newClient(rec)
+	want := []string{}
+	for _, p := range []string{"/a?x=1&y=2", "/b"} {
+		echo, err := post(t, c, server.URL, p, "body "+p)
+		if err != nil {
+			t.Fatalf("Error recording %s: %v", p, err)
+		}
+		want = append(want, echo)
+	}
+	_, err = post(t, c, server.URL, "/missing", "")
+	if !sourcehut.IsNotFound(err) {
+		t.Fatalf("Expected not found error while recording, got: %v", err)
+	}
+	err = rec.Close()
+	if err != nil {
+		t.Fatalf("Error writing cassette: %v", err)
+	}
+
+	raw, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte(secret)) || bytes.Contains(raw, []byte("Authorization")) {
+		t.Errorf("Cassette contains the access token:\n%s", raw)
+	}
+
+	// Replay against a different host without any network access.
+	served = 0
+	rec, err = replay.New(cassette, replay.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = newClient(rec)
+	base := "https://example.invalid"
+	// Query parameters are matched regardless of their order.
+	for i, p := range []string{"/a?y=2&x=1", "/b"} {
+		echo, err := post(t, c, base, p, "body "+strings.Replace(p, "y=2&x=1", "x=1&y=2", 1))
+		if err != nil {
+			t.Fatalf("Error replaying %s: %v", p, err)
+		}
+		if echo != want[i] {
+			t.Errorf("Unexpected replayed response: want=%q, got=%q", want[i], echo)
+		}
+	}
+	if err := rec.Close(); err == nil {
+		t.Errorf("Expected error for unused interaction")
+	}
+	_, err = post(t, c, base, "/missing", "")
+	if !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected replayed not found error, got: %v", err)
+	}
+	_, err = post(t, c, base, "/b", "body /b")
+	if !errors.Is(err, replay.ErrNoMatch) {
+		t.Errorf("Expected interactions to only be used once, got: %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Errorf("Unexpected error closing replay: %v", err)
+	}
+	if served != 0 {
+		t.Errorf("Replay made %d requests to the server", served)
+	}
+}
+
+func TestReplayStrict(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	err := os.WriteFile(cassette, []byte(`{"interactions": [{
+		"request": {"method": "POST", "url": "/api/repos?start=1", "body": "{\"name\":\"a\"}"},
+		"response": {"status_code": 200, "body": "{}"}
+	}]}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		method string
+		url    string
+		body   string
+		match  bool
+	}{
+		{method: "POST", url: "/api/repos?start=1", body: `{"name":"a"}`, match: true},
+		{method: "PUT", url: "/api/repos?start=1", body: `{"name":"a"}`},
+		{method: "POST", url: "/api/other?start=1", body: `{"name":"a"}`},
+		{method: "POST", url: "/api/repos?start=2", body: `{"name":"a"}`},
+		{method: "POST", url: "/api/repos", body: `{"name":"a"}`},
+		{method: "POST", url: "/api/repos?start=1", body: `{"name":"b"}`},
+	} {
+		t.Run(fmt.Sprintf("%s %s %s", tc.method, tc.url, tc.body), func(t *testing.T) {
+			rec, err := replay.New(cassette, replay.ModeReplay, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(tc.method, "https://example.invalid"+tc.url, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := rec.RoundTrip(req)
+			switch {
+			case tc.match && err != nil:
+				t.Fatalf("Unexpected error: %v", err)
+			case tc.match:
+				resp.Body.Close()
+			case !errors.Is(err, replay.ErrNoMatch):
+				t.Fatalf("Expected ErrNoMatch, got: %v", err)
+			}
+		})
+	}
+}
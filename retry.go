@@ -0,0 +1,203 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the Transport retries requests that fail with a
+// transient error.
+//
+// A request is retried if sending it fails with a network error or if the
+// server responds with 429 Too Many Requests or any 5xx status code.
+// If the response contains a Retry-After header it is used instead of the
+// computed backoff.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times a request will be sent,
+	// including the first attempt.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry.
+	// Each subsequent retry doubles the previous delay.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts, including delays requested
+	// by a Retry-After header.
+	// If zero, delays are not capped.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction of each delay (between 0 and 1) that is
+	// randomized to avoid many clients retrying in lockstep.
+	Jitter float64
+
+	// RetryAll allows retrying requests with methods that are not idempotent,
+	// such as POST and PATCH.
+	// By default only idempotent requests are retried.
+	RetryAll bool
+}
+
+// DefaultRetryPolicy is a RetryPolicy that is suitable for most clients.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+	Jitter:      0.2,
+}
+
+// Retry returns an option that configures the client to retry requests that
+// fail with transient errors according to the provided policy.
+// If unspecified, requests are never retried.
+func Retry(p RetryPolicy) Option {
+	return func(rt *Transport) {
+		rt.retry = p
+	}
+}
+
+// canRetry reports whether req may be sent more than once.
+func (p RetryPolicy) canRetry(req *http.Request) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+	// If the body can't be rewound we only get one shot at sending it.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if p.RetryAll {
+		return true
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether the result of an attempt indicates a transient
+// failure.
+func (p RetryPolicy) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		// If the client side rate limiter is configured to fail fast, respect
+		// that instead of waiting and trying again.
+		return !errors.Is(err, errLimitExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// backoff returns the delay before the provided retry (starting at 1).
+func (p RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	d, ok := retryAfter(resp, time.Now())
+	if !ok {
+		d = p.MinBackoff
+		for i := 1; i < retry && (p.MaxBackoff == 0 || d < p.MaxBackoff); i++ {
+			d *= 2
+		}
+		if p.Jitter > 0 {
+			/* #nosec */
+			d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// retryAfter parses the Retry-After header of resp, if any.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// roundTrip sends req using rt, retrying transient failures according to
+// the policy.
+// Retries are logged to l if it is not nil.
+func (p RetryPolicy) roundTrip(rt http.RoundTripper, req *http.Request, l *slog.Logger) (*http.Response, error) {
+	if !p.canRetry(req) {
+		return rt.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		resp, err := rt.RoundTrip(r)
+		if attempt >= p.MaxAttempts || !p.shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := p.backoff(attempt, resp)
+		if l != nil {
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.Redacted()),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", delay),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+			} else {
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			}
+			l.LogAttrs(ctx, slog.LevelDebug, "retrying request", attrs...)
+		}
+		if resp != nil {
+			// Drain the body so that the connection can be reused.
+			/* #nosec */
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			/* #nosec */
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+)
+
+var testRetryPolicy = sourcehut.RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+type retryTest struct {
+	method     string
+	body       string
+	policy     sourcehut.RetryPolicy
+	failures   int
+	failCode   int
+	retryAfter string
+	attempts   int32
+	code       int
+	minElapsed time.Duration
+}
+
+var retryTests = [...]retryTest{
+	0: {method: "GET", policy: testRetryPolicy, attempts: 1, code: 200},
+	1: {method: "GET", failures: 2, failCode: 503, attempts: 1, code: 503},
+	2: {method: "GET", policy: testRetryPolicy, failures: 2, failCode: 503, attempts: 3, code: 200},
+	3: {method: "GET", policy: testRetryPolicy, failures: 5, failCode: 502, attempts: 3, code: 502},
+	4: {method: "GET", policy: testRetryPolicy, failures: 1, failCode: 404, attempts: 1, code: 404},
+	5: {method: "POST", body: "body", policy: testRetryPolicy, failures: 1, failCode: 503, attempts: 1, code: 503},
+	6: {
+		method:   "POST",
+		body:     "body",
+		policy:   sourcehut.RetryPolicy{MaxAttempts: 3, RetryAll: true},
+		failures: 2, failCode: 500, attempts: 3, code: 200,
+	},
+	7: {method: "PUT", body: "body", policy: testRetryPolicy, failures: 1, failCode: 429, attempts: 2, code: 200},
+	8: {
+		method: "GET", policy: testRetryPolicy,
+		failures: 1, failCode: 429, retryAfter: "1",
+		attempts: 2, code: 200, minElapsed: time.Second,
+	},
+	9: {
+		method: "GET", policy: testRetryPolicy,
+		failures: 1, failCode: 503, retryAfter: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat),
+		attempts: 2, code: 200,
+	},
+}
+
+func TestRetry(t *testing.T) {
+	for i, tc := range retryTests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Errorf("Error reading request body: %v", err)
+				}
+				if string(body) != tc.body {
+					t.Errorf("Unexpected body on attempt %d: want=%q, got=%q", n, tc.body, body)
+				}
+				if int(n) <= tc.failures {
+					if tc.retryAfter != "" {
+						w.Header().Set("Retry-After", tc.retryAfter)
+					}
+					w.WriteHeader(tc.failCode)
+					return
+				}
+			}))
+			server.Config.ErrorLog = testlog.New(t)
+			server.Start()
+			defer server.Close()
+
+			rt := sourcehut.NewTransport(
+				sourcehut.Token("token"),
+				sourcehut.UserAgent("test"),
+				sourcehut.RoundTripper(server.Client().Transport),
+				sourcehut.Retry(tc.policy),
+			)
+			req, err := http.NewRequest(tc.method, server.URL, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+			if elapsed := time.Since(start); elapsed < tc.minElapsed {
+				t.Errorf("Retried too quickly: want>=%v, got=%v", tc.minElapsed, elapsed)
+			}
+			if resp.StatusCode != tc.code {
+				t.Errorf("Unexpected status code: want=%d, got=%d", tc.code, resp.StatusCode)
+			}
+			if n := atomic.LoadInt32(&attempts); n != tc.attempts {
+				t.Errorf("Unexpected number of attempts: want=%d, got=%d", tc.attempts, n)
+			}
+		})
+	}
+}
@@ -7,11 +7,12 @@
 package sourcehut
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"log/slog"
 	"net/http"
-
-	"git.sr.ht/~wombelix/sourcehut-go/logger"
 )
 
 // Option is used to configure a Sourcehut API client.
@@ -27,14 +28,47 @@ func UserAgent(ua string) Option {
 
 // Token returns an option that configures the client to use the provided access
 // token when making API requests.
-// If no token is provided, the client can only make requests that do not
-// require authentication.
+// If no token is provided, requests fail unless the client is anonymous (see
+// Anonymous).
 func Token(t string) Option {
 	return func(rt *Transport) {
 		rt.accessToken = t
 	}
 }
 
+// AccessTokenSource provides access tokens that may change over time, such as
+// OAuth 2.0 tokens that expire and have to be refreshed.
+//
+// AccessToken is called before every request and must be safe for concurrent
+// use.
+type AccessTokenSource interface {
+	AccessToken(ctx context.Context) (string, error)
+}
+
+// TokenSource returns an option that configures the client to authenticate
+// requests using OAuth 2.0 bearer tokens obtained from src.
+// It takes precedence over any token configured using the Token option.
+func TokenSource(src AccessTokenSource) Option {
+	return func(rt *Transport) {
+		rt.tokenSource = src
+	}
+}
+
+// Anonymous returns an option that configures the client to make
+// unauthenticated requests, which can only read public data such as another
+// user's public repositories or a public mailing list archive.
+// No Authorization header is sent, and any token configured with Token or
+// TokenSource is ignored.
+// A user agent is still required.
+//
+// The service clients return an error matching ErrAuthRequired from methods
+// that need authentication without making a request.
+func Anonymous() Option {
+	return func(rt *Transport) {
+		rt.anonymous = true
+	}
+}
+
 // RoundTripper returns an option that configures the client to use the provided
 // http.RoundTripper for HTTP requests.
 // If unspecified, http.DefaultTransport is used.
@@ -44,6 +78,17 @@ func RoundTripper(rt http.RoundTripper) Option {
 	}
 }
 
+// Logger returns an option that configures the client to log to l.
+// Every request that is sent is logged at the debug level with its method, URL,
+// status, and duration (see LogRequests), as are retries.
+// Access tokens and other credentials are never logged.
+// If unspecified, nothing is logged.
+func Logger(l *slog.Logger) Option {
+	return func(t *Transport) {
+		t.logger = l
+	}
+}
+
 // Transport is an http.RoundTripper wrapping a base RoundTripper and adding a
 // Sourcehut API authorization header or user agent.
 //
@@ -52,7 +97,15 @@ func RoundTripper(rt http.RoundTripper) Option {
 type Transport struct {
 	userAgent   string
 	accessToken string
+	tokenSource AccessTokenSource
+	anonymous   bool
 	baseRT      http.RoundTripper
+	retry       RetryPolicy
+	limiter     *rateLimiter
+	middleware  []Middleware
+	intercept   []Middleware
+	logger      *slog.Logger
+	fields      fieldCheck
 }
 
 // NewTransport returns an http.RoundTripper that is configured with the
@@ -67,45 +120,72 @@ func NewTransport(opts ...Option) *Transport {
 
 // RoundTrip authorizes and authenticates the request with an
 // access token from Transport's Source.
+//
+// As required by the http.RoundTripper contract, req is not modified: the
+// headers are set on a clone of the request.
 func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if req.Body != nil {
-		defer func() {
+	authorization, err := t.authorization(req.Context())
+	if err == nil && t.userAgent == "" {
+		err = errors.New("no user agent configured")
+	}
+	if err != nil {
+		// RoundTrip must always close the body, even if the request is never
+		// sent.
+		if req.Body != nil {
 			/* #nosec */
 			req.Body.Close()
-		}()
+		}
+		return nil, err
 	}
 
-	// TODO: clone req.
-
-	if t.accessToken == "" {
-		return nil, errors.New("no access token provided")
-	}
+	req = req.Clone(req.Context())
 
 	// TODO: do we need to sanitize this to prevent header injection in case the
 	// user takes this value from somewhere they shouldn't?
-	req.Header.Set("Authorization", "token "+t.accessToken)
-
-	if t.userAgent == "" {
-		return nil, errors.New("no user agent configured")
+	if authorization == "" {
+		req.Header.Del("Authorization")
+	} else {
+		req.Header.Set("Authorization", authorization)
 	}
 
 	// TODO: do we need to sanitize this to prevent header injection in case the
 	// user takes this value from somewhere they shouldn't?
 	req.Header.Set("User-Agent", t.userAgent)
 
-	return t.base().RoundTrip(req)
+	base := t.base()
+	if t.logger != nil {
+		base = LogRequests(t.logger)(base)
+	}
+	next := chain(base, t.middleware)
+	send := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return t.limiter.roundTrip(next, r)
+	})
+	retry := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return t.retry.roundTrip(send, r, t.logger)
+	})
+	return chain(retry, t.intercept).RoundTrip(req)
 }
 
-// CancelRequest cancels an in-flight request by closing its connection.
-//
-// If the underlying http.RoundTripper does not support cancelation,
-// CancelRequest is a noop.
-func (t *Transport) CancelRequest(req *http.Request) {
-	if c, ok := t.base().(interface {
-		CancelRequest(*http.Request)
-	}); ok {
-		c.CancelRequest(req)
+// authorization returns the value of the Authorization header, or an empty
+// string if the transport is anonymous.
+func (t *Transport) authorization(ctx context.Context) (string, error) {
+	if t.anonymous {
+		return "", nil
 	}
+	if t.tokenSource != nil {
+		tok, err := t.tokenSource.AccessToken(ctx)
+		if err != nil {
+			return "", err
+		}
+		if tok == "" {
+			return "", errors.New("no access token provided")
+		}
+		return "Bearer " + tok, nil
+	}
+	if t.accessToken == "" {
+		return "", errors.New("no access token provided")
+	}
+	return "token " + t.accessToken, nil
 }
 
 func (t *Transport) base() http.RoundTripper {
@@ -115,10 +195,21 @@ func (t *Transport) base() http.RoundTripper {
 	return http.DefaultTransport
 }
 
+// roundTripperFunc is an adapter that allows the use of an ordinary function as
+// an http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
 // Client is like http.Client except that it knows how to authenticate to the
 // Sourcehut API.
 type Client struct {
 	httpClient *http.Client
+	fields     fieldCheck
+	anonymous  bool
 }
 
 // NewBaseClient returns a new Sourcehut API client configured to use the
@@ -133,21 +224,41 @@ func NewBaseClient(base *http.Client) Client {
 // NewClient returns a new Sourcehut API client configured with the provided
 // options.
 func NewClient(opts ...Option) Client {
+	t := NewTransport(opts...)
 	return Client{
 		httpClient: &http.Client{
-			Transport: NewTransport(opts...),
+			Transport: t,
 		},
+		fields:    t.fields,
+		anonymous: t.anonymous,
 	}
 }
 
+// Anonymous reports whether the client was created with the Anonymous option.
+func (c Client) Anonymous() bool {
+	return c.anonymous
+}
+
+// RequireAuth returns an *AuthRequiredError if the client is anonymous.
+// The call being made is taken from the CallInfo in ctx and included in the
+// error.
+func (c Client) RequireAuth(ctx context.Context) error {
+	if !c.anonymous {
+		return nil
+	}
+	info, _ := CallInfoFromContext(ctx)
+	return &AuthRequiredError{Service: info.Service, Endpoint: info.Endpoint}
+}
+
 // Do sends an API request and returns the API response.
 // The response is unmarshaled into v if successful, or returned as an error
 // value if an API error has occured.
+//
+// The request is canceled if the context attached to req is canceled or its
+// deadline is exceeded.
+// Requests should normally be created with http.NewRequestWithContext.
 func (c Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 	resp, err := c.do(req)
-
-	logger.Log.Debugf("req.Header: %v", req.Header)
-
 	if err != nil {
 		return resp, err
 	}
@@ -157,25 +268,52 @@ func (c Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 	}()
 
 	if v != nil {
-		err = json.NewDecoder(resp.Body).Decode(v)
+		if !c.fields.enabled() {
+			return resp, json.NewDecoder(resp.Body).Decode(v)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp, err
+		}
+		err = json.Unmarshal(body, v)
 		if err != nil {
 			return resp, err
 		}
+		return resp, c.fields.check(body, v)
 	}
 
 	return resp, nil
 }
 
+// Send sends an API request and returns the response without reading its body,
+// for responses that are too large to decode all at once.
+// API errors are returned in the same way as by Do.
+//
+// If the returned error is nil, the caller must close the response body.
+func (c Client) Send(req *http.Request) (*http.Response, error) {
+	return c.do(req)
+}
+
 // List returns an iterator that can transparently make API requests to a
 // paginated endpoint.
 // Each item will be decoded into the value returned from a call to d.
 // If d is nil, a map[string]interface{} is created for each item.
 //
 // No HTTP request will be issued until iteration is started by a call to Next.
-func (c Client) List(req *http.Request, d func() interface{}) *Iter {
-	return &Iter{req: req, c: c, into: d}
+// The context attached to req is used for every page that is fetched and once
+// it is canceled no further pages will be requested.
+//
+// List predates the generic Iter type; new code should prefer the List
+// function, which does not require type assertions on the items.
+func (c Client) List(req *http.Request, d func() interface{}) *Iter[interface{}] {
+	open := restOpen(c, req)
+	return &Iter[interface{}]{ctx: req.Context(), page: restPage(open), open: open, into: d, fields: c.fields}
 }
 
+// maxErrorBody is the maximum number of bytes of an error response that will
+// be read.
+const maxErrorBody = 1 << 20
+
 func (c Client) do(req *http.Request) (*http.Response, error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -184,16 +322,18 @@ func (c Client) do(req *http.Request) (*http.Response, error) {
 
 	if resp.StatusCode >= 400 {
 		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		if err != nil {
+			return resp, err
+		}
 		e := struct {
 			Errors Errors `json:"errors"`
 		}{}
-		err = json.NewDecoder(resp.Body).Decode(&e)
-		if err != nil {
-			return resp, err
+		err = json.Unmarshal(body, &e)
+		if err != nil || len(e.Errors) == 0 {
+			return resp, HTTPError{Body: body, statusCode: resp.StatusCode}
 		}
 		switch len(e.Errors) {
-		case 0:
-			return resp, nil
 		case 1:
 			e.Errors[0].statusCode = resp.StatusCode
 			return resp, e.Errors[0]
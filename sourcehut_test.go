@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package sourcehut_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+)
+
+// pagedServer serves pages of sequential integers, total items in pages of
+// size per page.
+func pagedServer(t *testing.T, total, size int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "token token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		start, _ := strconv.Atoi(req.URL.Query().Get("start"))
+		end := min(start+size, total)
+		results := make([]string, 0, end-start)
+		for n := start; n < end; n++ {
+			results = append(results, strconv.Itoa(n))
+		}
+		next := "null"
+		if end < total {
+			next = strconv.Quote(strconv.Itoa(end))
+		}
+		fmt.Fprintf(w, `{"next": %s, "results": [%s], "results_per_page": %d, "total": %d}`,
+			next, strings.Join(results, ","), size, total)
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRoundTripDoesNotModifyRequest(t *testing.T) {
+	server := pagedServer(t, 3, 1)
+	client := sourcehut.NewClient(
+		sourcehut.Token("token"),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+	)
+	req, err := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/items?q=x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	for _, err := range sourcehut.List[int](client, req).All() {
+		if err != nil {
+			t.Fatalf("Error iterating: %v", err)
+		}
+		n++
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 items, got %d", n)
+	}
+	if len(req.Header) != 0 {
+		t.Errorf("Request headers were modified: %v", req.Header)
+	}
+	if req.URL.RawQuery != "q=x" {
+		t.Errorf("Request URL was modified: %s", req.URL)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	const (
+		total   = 25
+		workers = 8
+	)
+	server := pagedServer(t, total, 4)
+	client := sourcehut.NewClient(
+		sourcehut.Token("token"),
+		sourcehut.UserAgent("test"),
+		sourcehut.RoundTripper(server.Client().Transport),
+		sourcehut.Retry(sourcehut.DefaultRetryPolicy),
+		sourcehut.RateLimit(sourcehut.RateLimitPolicy{Rate: 10000, Burst: 100}),
+	)
+	// A single request is shared by all goroutines.
+	req, err := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			want := 0
+			for v, err := range sourcehut.List[int](client, req).All() {
+				if err != nil {
+					errs <- err
+					return
+				}
+				if v != want {
+					errs <- fmt.Errorf("unexpected item: want=%d, got=%d", want, v)
+					return
+				}
+				want++
+			}
+			if want != total {
+				errs <- fmt.Errorf("expected %d items, got %d", total, want)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var page sourcehut.Response
+			_, err := client.Do(req, &page)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if page.Total != total {
+				errs <- fmt.Errorf("unexpected total: want=%d, got=%d", total, page.Total)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+var anonymousTests = [...]struct {
+	opts []sourcehut.Option
+	auth string
+	err  bool
+}{
+	0: {opts: []sourcehut.Option{sourcehut.UserAgent("test")}, err: true},
+	1: {opts: []sourcehut.Option{sourcehut.UserAgent("test"), sourcehut.Token("token")}, auth: "token token"},
+	2: {opts: []sourcehut.Option{sourcehut.UserAgent("test"), sourcehut.Anonymous()}},
+	3: {opts: []sourcehut.Option{sourcehut.UserAgent("test"), sourcehut.Token("token"), sourcehut.Anonymous()}},
+	4: {opts: []sourcehut.Option{sourcehut.Anonymous()}, err: true},
+}
+
+func TestAnonymous(t *testing.T) {
+	var auth []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		auth = req.Header.Values("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+
+	for i, tc := range anonymousTests {
+		auth = nil
+		client := sourcehut.NewClient(append(tc.opts, sourcehut.RoundTripper(server.Client().Transport))...)
+		req, err := http.NewRequest("GET", server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Headers set by the caller must not leak credentials either.
+		req.Header.Set("Authorization", "token leaked")
+		_, err = client.Do(req, nil)
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("%d: expected error", i)
+		case !tc.err && err != nil:
+			t.Errorf("%d: unexpected error: %v", i, err)
+		case tc.err:
+			continue
+		}
+		if got := strings.Join(auth, ", "); got != tc.auth {
+			t.Errorf("%d: wrong authorization header: want=%q, got=%q", i, tc.auth, got)
+		}
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	ctx := sourcehut.WithCallInfo(context.Background(), sourcehut.CallInfo{Service: "git", Endpoint: "repos"})
+	if err := sourcehut.NewClient(sourcehut.Token("token")).RequireAuth(ctx); err != nil {
+		t.Errorf("Unexpected error from authenticated client: %v", err)
+	}
+
+	client := sourcehut.NewClient(sourcehut.Anonymous())
+	if !client.Anonymous() {
+		t.Errorf("Expected anonymous client")
+	}
+	err := client.RequireAuth(ctx)
+	if !errors.Is(err, sourcehut.ErrAuthRequired) || !sourcehut.IsUnauthorized(err) {
+		t.Errorf("Expected auth required error, got: %v", err)
+	}
+	const msg = "git repos requires authentication, but the client is anonymous"
+	if err != nil && err.Error() != msg {
+		t.Errorf("Wrong error message: want=%q, got=%q", msg, err.Error())
+	}
+}
+
+func TestEndpointsAuthorize(t *testing.T) {
+	endpoints := sourcehut.Endpoints{
+		Service: sourcehut.ServiceGit,
+		Public:  map[string]bool{"user/{username}": true},
+	}
+	anon := sourcehut.NewClient(sourcehut.Anonymous())
+	authed := sourcehut.NewClient(sourcehut.Token("token"))
+	for i, tc := range [...]struct {
+		ctx    context.Context
+		method string
+		client sourcehut.Client
+		err    bool
+	}{
+		0: {ctx: endpoints.UserCall(context.Background(), "~test"), method: "GET", client: anon},
+		1: {ctx: endpoints.UserCall(context.Background(), "~test"), method: "PUT", client: anon, err: true},
+		2: {ctx: endpoints.UserCall(context.Background(), ""), method: "GET", client: anon, err: true},
+		3: {ctx: endpoints.Call(context.Background(), "repos"), method: "GET", client: anon, err: true},
+		4: {ctx: endpoints.Call(context.Background(), "repos"), method: "POST", client: authed},
+	} {
+		err := endpoints.Authorize(tc.ctx, tc.client, tc.method)
+		if (err != nil) != tc.err {
+			t.Errorf("%d: unexpected error: %v", i, err)
+		}
+		var authErr *sourcehut.AuthRequiredError
+		if tc.err && (!errors.As(err, &authErr) || authErr.Service != sourcehut.ServiceGit) {
+			t.Errorf("%d: expected auth required error for git, got: %v", i, err)
+		}
+	}
+}
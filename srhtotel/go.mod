@@ -0,0 +1,24 @@
+module git.sr.ht/~wombelix/sourcehut-go/srhtotel
+
+go 1.23
+
+require (
+	git.sr.ht/~wombelix/sourcehut-go v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.35.0
+	go.opentelemetry.io/otel/metric v1.35.0
+	go.opentelemetry.io/otel/sdk v1.35.0
+	go.opentelemetry.io/otel/sdk/metric v1.35.0
+	go.opentelemetry.io/otel/trace v1.35.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
+
+// Build against the root module in this repository.
+// Require a tagged version of the root module before tagging this one.
+replace git.sr.ht/~wombelix/sourcehut-go => ../
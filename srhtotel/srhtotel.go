@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package srhtotel instruments Sourcehut API clients with OpenTelemetry
+// tracing and metrics.
+//
+// The instrumentation is added to a client as middleware:
+//
+//	srhtClient := sourcehut.NewClient(
+//		sourcehut.Token(token),
+//		sourcehut.UserAgent(userAgent),
+//		sourcehut.Use(srhtotel.Middleware()),
+//	)
+//
+// A span is started for every HTTP request and the request's duration is
+// recorded, both annotated with the service, endpoint, and page number that
+// the service clients attach to requests (see sourcehut.CallInfo).
+// Because middleware runs once per attempt, retries are recorded as separate
+// spans; to record a single span per call instead, wrap the whole transport:
+//
+//	rt := srhtotel.Middleware()(sourcehut.NewTransport(opts...))
+//	srhtClient := sourcehut.NewBaseClient(&http.Client{Transport: rt})
+//
+// The package is a separate module so that users of the API clients who do not
+// need the instrumentation do not depend on OpenTelemetry.
+package srhtotel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+)
+
+// ScopeName is the instrumentation scope used for the tracer and meter.
+const ScopeName = "git.sr.ht/~wombelix/sourcehut-go/srhtotel"
+
+// Attribute keys that are specific to Sourcehut.
+// Other attributes follow the OpenTelemetry semantic conventions for HTTP
+// clients.
+const (
+	ServiceKey  = attribute.Key("sourcehut.service")
+	EndpointKey = attribute.Key("sourcehut.endpoint")
+	PageKey     = attribute.Key("sourcehut.page")
+)
+
+// Option is used to configure the instrumentation.
+type Option func(*config)
+
+type config struct {
+	tp trace.TracerProvider
+	mp metric.MeterProvider
+}
+
+// TracerProvider returns an option that configures the provider used to create
+// spans.
+// If unspecified, the global provider is used.
+func TracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tp = tp
+	}
+}
+
+// MeterProvider returns an option that configures the provider used to record
+// metrics.
+// If unspecified, the global provider is used.
+func MeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.mp = mp
+	}
+}
+
+type instruments struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+}
+
+// Middleware returns sourcehut.Middleware that records a span and metrics for
+// every request.
+//
+// The following metrics are recorded:
+//
+//   - sourcehut.client.request.duration: a histogram of request durations in
+//     seconds
+//   - sourcehut.client.requests: a count of requests
+//   - sourcehut.client.errors: a count of requests that failed or received a
+//     response with an error status
+func Middleware(opts ...Option) sourcehut.Middleware {
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.tp == nil {
+		c.tp = otel.GetTracerProvider()
+	}
+	if c.mp == nil {
+		c.mp = otel.GetMeterProvider()
+	}
+
+	meter := c.mp.Meter(ScopeName)
+	inst := instruments{tracer: c.tp.Tracer(ScopeName)}
+	var err error
+	inst.duration, err = meter.Float64Histogram("sourcehut.client.request.duration",
+		metric.WithDescription("Duration of Sourcehut API requests."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	inst.requests, err = meter.Int64Counter("sourcehut.client.requests",
+		metric.WithDescription("Number of Sourcehut API requests."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	inst.errors, err = meter.Int64Counter("sourcehut.client.errors",
+		metric.WithDescription("Number of Sourcehut API requests that failed."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripper{next: next, inst: inst}
+	}
+}
+
+type roundTripper struct {
+	next http.RoundTripper
+	inst instruments
+}
+
+func (rt roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	info, _ := sourcehut.CallInfoFromContext(req.Context())
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Hostname()),
+	}
+	if info.Service != "" {
+		attrs = append(attrs, ServiceKey.String(info.Service))
+	}
+	if info.Endpoint != "" {
+		attrs = append(attrs, EndpointKey.String(info.Endpoint))
+	}
+
+	ctx, span := rt.inst.tracer.Start(req.Context(), spanName(req, info),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(attribute.String("url.full", req.URL.Redacted())),
+	)
+	defer span.End()
+	if info.Page > 0 {
+		span.SetAttributes(PageKey.Int(info.Page))
+	}
+
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req.WithContext(ctx))
+	elapsed := time.Since(start).Seconds()
+
+	failed := err != nil
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		attrs = append(attrs, attribute.String("error.type", errorType(err)))
+	default:
+		attrs = append(attrs, attribute.Int("http.response.status_code", resp.StatusCode))
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			failed = true
+			span.SetStatus(codes.Error, resp.Status)
+			attrs = append(attrs, attribute.String("error.type", strconv.Itoa(resp.StatusCode)))
+		}
+	}
+
+	set := metric.WithAttributeSet(attribute.NewSet(attrs...))
+	rt.inst.duration.Record(ctx, elapsed, set)
+	rt.inst.requests.Add(ctx, 1, set)
+	if failed {
+		rt.inst.errors.Add(ctx, 1, set)
+	}
+	return resp, err
+}
+
+// spanName returns the name of the span for a request, eg. "GET git
+// repos/{name}", falling back to just the method for requests that were not
+// made by a service client.
+func spanName(req *http.Request, info sourcehut.CallInfo) string {
+	if info.Endpoint == "" {
+		return req.Method
+	}
+	if info.Service == "" {
+		return req.Method + " " + info.Endpoint
+	}
+	return req.Method + " " + info.Service + " " + info.Endpoint
+}
+
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return "_OTHER"
+}
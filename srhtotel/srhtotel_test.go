@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package srhtotel_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+	"git.sr.ht/~wombelix/sourcehut-go/srhtotel"
+	"git.sr.ht/~wombelix/sourcehut-go/srhttest"
+)
+
+func TestMiddleware(t *testing.T) {
+	srv := srhttest.NewServer()
+	defer srv.Close()
+	srv.SetPageSize(2)
+	for _, name := range []string{"a", "b", "c"} {
+		srv.AddRepo(srhttest.Username, name, "", git.VisibilityPublic)
+	}
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	client, err := git.NewClient(
+		git.SrhtClient(sourcehut.NewClient(
+			sourcehut.Token(srhttest.Token),
+			sourcehut.UserAgent("test"),
+			sourcehut.RoundTripper(srv.HTTPClient().Transport),
+			sourcehut.Use(srhtotel.Middleware(
+				srhtotel.TracerProvider(tp),
+				srhtotel.MeterProvider(mp),
+			)),
+		)),
+		git.Base(srv.URL(srhttest.Git)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repos, err := client.Repos(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range repos.All() {
+		if err != nil {
+			t.Fatalf("Error listing repos: %v", err)
+		}
+	}
+	_, err = client.Repo(ctx, "", "missing")
+	if !sourcehut.IsNotFound(err) {
+		t.Fatalf("Expected not found error, got: %v", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(ended))
+	}
+	for i, span := range ended {
+		attrs := attribute.NewSet(span.Attributes()...)
+		service, _ := attrs.Value(srhtotel.ServiceKey)
+		endpoint, _ := attrs.Value(srhtotel.EndpointKey)
+		page, _ := attrs.Value(srhtotel.PageKey)
+		status, _ := attrs.Value("http.response.status_code")
+		if service.AsString() != "git" {
+			t.Errorf("Span %d has unexpected service %q", i, service.AsString())
+		}
+		switch i {
+		case 0, 1:
+			if span.Name() != "GET git repos" || endpoint.AsString() != "repos" {
+				t.Errorf("Unexpected span %d: %s %v", i, span.Name(), span.Attributes())
+			}
+			if page.AsInt64() != int64(i+1) {
+				t.Errorf("Span %d has unexpected page %d", i, page.AsInt64())
+			}
+			if status.AsInt64() != 200 || span.Status().Code == codes.Error {
+				t.Errorf("Span %d has unexpected status: %d, %v", i, status.AsInt64(), span.Status())
+			}
+		case 2:
+			if span.Name() != "GET git repos/{name}" || attrs.HasValue(srhtotel.PageKey) {
+				t.Errorf("Unexpected span %d: %s %v", i, span.Name(), span.Attributes())
+			}
+			if status.AsInt64() != 404 || span.Status().Code != codes.Error {
+				t.Errorf("Span %d has unexpected status: %d, %v", i, status.AsInt64(), span.Status())
+			}
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	err = reader.Collect(ctx, &rm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += int64(dp.Count)
+				}
+			}
+		}
+	}
+	for name, want := range map[string]int64{
+		"sourcehut.client.requests":         3,
+		"sourcehut.client.errors":           1,
+		"sourcehut.client.request.duration": 3,
+	} {
+		if counts[name] != want {
+			t.Errorf("Unexpected value for %s: want=%d, got=%d", name, want, counts[name])
+		}
+	}
+}
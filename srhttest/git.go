@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package srhttest
+
+import (
+	"net/http"
+
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+)
+
+// AddRepo creates a repository owned by the named user, creating the user if
+// it does not exist.
+func (s *Server) AddRepo(owner, name, description string, visibility git.RepoVisibility) git.Repo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, _ := s.shortUser(owner)
+	r := &git.Repo{
+		ID:          s.id(),
+		Created:     s.now(),
+		Name:        name,
+		Description: description,
+		Visibility:  visibility,
+	}
+	u.repos = append(u.repos, r)
+	return *r
+}
+
+func (s *Server) serveGit(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	if parts[0] == "user" {
+		return s.serveUser(w, req, me, parts)
+	}
+
+	owner := me
+	if parts[0] != "repos" {
+		// ~username/repos[/name]
+		if len(parts) < 2 || parts[1] != "repos" {
+			return false
+		}
+		u, ok := s.lookupUser(parts[0])
+		if !ok {
+			writeError(w, http.StatusNotFound, "", "No such user")
+			return true
+		}
+		owner = u
+		parts = parts[1:]
+		if req.Method != "GET" {
+			return false
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		switch req.Method {
+		case "GET":
+			repos := owner.repos
+			if owner != me {
+				repos = nil
+				for _, r := range owner.repos {
+					if r.Visibility == git.VisibilityPublic {
+						repos = append(repos, r)
+					}
+				}
+			}
+			writePage(w, req, s.pageSize, repos)
+		case "POST":
+			var body struct {
+				Name        string             `json:"name"`
+				Description string             `json:"description"`
+				Visibility  git.RepoVisibility `json:"visibility"`
+			}
+			if !readJSON(w, req, &body) {
+				return true
+			}
+			if body.Name == "" {
+				writeError(w, http.StatusBadRequest, "name", "Name is required")
+				return true
+			}
+			if !validVisibility(body.Visibility) {
+				writeError(w, http.StatusBadRequest, "visibility", "Invalid visibility")
+				return true
+			}
+			if body.Visibility == "" {
+				body.Visibility = git.VisibilityPublic
+			}
+			if s.findRepo(owner, body.Name) != nil {
+				writeError(w, http.StatusBadRequest, "name", "A repository with this name already exists")
+				return true
+			}
+			r := &git.Repo{
+				ID:          s.id(),
+				Created:     s.now(),
+				Name:        body.Name,
+				Description: body.Description,
+				Visibility:  body.Visibility,
+			}
+			owner.repos = append(owner.repos, r)
+			writeJSON(w, http.StatusCreated, r)
+		default:
+			return false
+		}
+	case 2:
+		r := s.findRepo(owner, parts[1])
+		if r == nil || (owner != me && r.Visibility == git.VisibilityPrivate) {
+			writeError(w, http.StatusNotFound, "", "No such repository")
+			return true
+		}
+		switch req.Method {
+		case "GET":
+			writeJSON(w, http.StatusOK, r)
+		case "PUT":
+			var body struct {
+				Name        *string             `json:"name"`
+				Description *string             `json:"description"`
+				Visibility  *git.RepoVisibility `json:"visibility"`
+			}
+			if !readJSON(w, req, &body) {
+				return true
+			}
+			if body.Visibility != nil && !validVisibility(*body.Visibility) {
+				writeError(w, http.StatusBadRequest, "visibility", "Invalid visibility")
+				return true
+			}
+			if body.Name != nil {
+				r.Name = *body.Name
+			}
+			if body.Description != nil {
+				r.Description = *body.Description
+			}
+			if body.Visibility != nil {
+				r.Visibility = *body.Visibility
+			}
+			writeJSON(w, http.StatusOK, r)
+		case "DELETE":
+			owner.repos, _ = remove(owner.repos, func(v *git.Repo) bool { return v == r })
+			writeNoContent(w)
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) findRepo(u *userState, name string) *git.Repo {
+	for _, r := range u.repos {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+func validVisibility(v git.RepoVisibility) bool {
+	switch v {
+	case "", git.VisibilityPublic, git.VisibilityUnlisted, git.VisibilityPrivate:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package srhttest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+)
+
+// AddOAuthGrant grants a new third party OAuth application access to the
+// account of the authenticated user and returns the grant.
+func (s *Server) AddOAuthGrant(clientName string) meta.OAuthGrant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	me := s.users[Username]
+	id := s.id()
+	now := s.now()
+	grant := &meta.OAuthGrant{
+		ID: id,
+		Client: meta.OAuthClient{
+			ID:          id,
+			UUID:        uuid(id),
+			Name:        clientName,
+			RedirectURL: "https://example.org/oauth/callback",
+		},
+		Issued:    now,
+		Expires:   now.AddDate(1, 0, 0),
+		TokenHash: fmt.Sprintf("%064x", id),
+	}
+	me.grants = append(me.grants, grant)
+	return *grant
+}
+
+// issued reports whether the Authorization header contains the secret of a
+// personal access token that was issued by the server and has not been
+// revoked.
+func (s *Server) issued(authorization string) bool {
+	_, secret, ok := strings.Cut(authorization, " ")
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok = s.secrets[secret]
+	return ok
+}
+
+// serveMetaGraphQL handles the subset of the meta GraphQL API that is used by
+// the meta package.
+// Queries are not parsed: the first field of the operation selects what is
+// returned and every field of the result is always included.
+func (s *Server) serveMetaGraphQL(w http.ResponseWriter, req *http.Request, me *userState) {
+	if req.Method != "POST" {
+		writeError(w, http.StatusNotFound, "", "Not found")
+		return
+	}
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if !readJSON(w, req, &body) {
+		return
+	}
+	vars := body.Variables
+	str := func(name string) string {
+		v, _ := vars[name].(string)
+		return v
+	}
+
+	var result interface{}
+	field := rootField(body.Query)
+	switch field {
+	case "personalAccessTokens":
+		result = append([]*meta.PersonalAccessToken{}, me.tokens...)
+	case "issuePersonalAccessToken":
+		now := s.now()
+		tok := &meta.PersonalAccessToken{
+			ID:      s.id(),
+			Issued:  now,
+			Expires: now.AddDate(1, 0, 0),
+			Comment: str("comment"),
+			Grants:  str("grants"),
+		}
+		secret := "srhttest-pat-" + strconv.FormatInt(tok.ID, 10)
+		me.tokens = append(me.tokens, tok)
+		s.secrets[secret] = tok.ID
+		s.audit(req, me, "personal-access-token:issue", "Personal access token issued")
+		result = struct {
+			Token  *meta.PersonalAccessToken `json:"token"`
+			Secret string                    `json:"secret"`
+		}{Token: tok, Secret: secret}
+	case "revokePersonalAccessToken":
+		id, _ := vars["id"].(float64)
+		for i, tok := range me.tokens {
+			if tok.ID != int64(id) {
+				continue
+			}
+			me.tokens = append(me.tokens[:i], me.tokens[i+1:]...)
+			for secret, tokID := range s.secrets {
+				if tokID == tok.ID {
+					delete(s.secrets, secret)
+				}
+			}
+			s.audit(req, me, "personal-access-token:revoke", "Personal access token revoked")
+			result = tok
+			break
+		}
+	case "oauthGrants":
+		result = append([]*meta.OAuthGrant{}, me.grants...)
+	case "revokeOAuthGrant":
+		for i, grant := range me.grants {
+			if grant.TokenHash == str("hash") {
+				me.grants = append(me.grants[:i], me.grants[i+1:]...)
+				result = grant
+				break
+			}
+		}
+	case "oauthClients":
+		result = append([]*meta.OAuthClient{}, me.clients...)
+	case "registerOAuthClient":
+		client := &meta.OAuthClient{
+			ID:          s.id(),
+			Name:        str("name"),
+			Description: str("description"),
+			URL:         str("url"),
+			RedirectURL: str("redirectUri"),
+		}
+		if client.Name == "" || client.RedirectURL == "" {
+			writeGraphQLError(w, "clientName", "Name and redirect URI are required")
+			return
+		}
+		client.UUID = uuid(client.ID)
+		me.clients = append(me.clients, client)
+		s.audit(req, me, "oauth-client:register", "OAuth client "+client.UUID+" registered")
+		result = struct {
+			Client *meta.OAuthClient `json:"client"`
+			Secret string            `json:"secret"`
+		}{Client: client, Secret: "srhttest-client-" + strconv.FormatInt(client.ID, 10)}
+	case "revokeOAuthClient":
+		for i, client := range me.clients {
+			if client.UUID != str("uuid") {
+				continue
+			}
+			me.clients = append(me.clients[:i], me.clients[i+1:]...)
+			me.grants, _ = remove(me.grants, func(g *meta.OAuthGrant) bool { return g.Client.UUID == client.UUID })
+			s.audit(req, me, "oauth-client:revoke", "OAuth client "+client.UUID+" revoked")
+			result = client
+			break
+		}
+	default:
+		writeGraphQLError(w, "", fmt.Sprintf("Cannot query field %q", field))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{field: result},
+	})
+}
+
+// rootField returns the name of the first field selected by a GraphQL
+// operation.
+func rootField(query string) string {
+	_, sel, _ := strings.Cut(query, "{")
+	sel = strings.TrimSpace(sel)
+	end := strings.IndexFunc(sel, func(r rune) bool {
+		return !(r == '_' || '0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+	})
+	if end == -1 {
+		return sel
+	}
+	return sel[:end]
+}
+
+func writeGraphQLError(w http.ResponseWriter, field, message string) {
+	type gqlError struct {
+		Message    string            `json:"message"`
+		Extensions map[string]string `json:"extensions,omitempty"`
+	}
+	e := gqlError{Message: message}
+	if field != "" {
+		e.Extensions = map[string]string{"field": field}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Data   interface{} `json:"data"`
+		Errors []gqlError  `json:"errors"`
+	}{
+		Errors: []gqlError{e},
+	})
+}
+
+// uuid returns a fake but well formed UUID derived from id.
+func uuid(id int64) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", id)
+}
@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package srhttest
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"git.sr.ht/~wombelix/sourcehut-go/lists"
+)
+
+// AddList creates a mailing list owned by the named user, creating the user if
+// it does not exist.
+func (s *Server) AddList(owner, name, description string) lists.List {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, _ := s.shortUser(owner)
+	return *s.newList(u, name, description)
+}
+
+// AddPost adds an email from sender to a mailing list.
+// The list, its owner, and the sender are created if they do not exist.
+func (s *Server) AddPost(owner, listName, sender, subject, envelope string) lists.Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, _ := s.shortUser(owner)
+	l := s.findList(u, listName)
+	if l == nil {
+		l = s.newList(u, listName, "")
+	}
+	_, from := s.shortUser(sender)
+	id := s.id()
+	p := &lists.Post{
+		ShortPost: lists.ShortPost{
+			ID:        id,
+			Created:   s.now(),
+			List:      l.ShortList,
+			MessageID: fmt.Sprintf("<%d@srhttest>", id),
+			Sender:    &from,
+			Subject:   subject,
+			ThreadID:  id,
+		},
+		Envelope: envelope,
+	}
+	u.posts = append(u.posts, p)
+	return *p
+}
+
+func (s *Server) newList(u *userState, name, description string) *lists.List {
+	l := &lists.List{
+		ShortList: lists.ShortList{
+			Name:  name,
+			Owner: u.user.ShortUser,
+		},
+		Created: s.now(),
+		Updated: s.now(),
+		Desc:    description,
+	}
+	l.Perms.NonSubscriber = []string{"browse", "reply", "post"}
+	l.Perms.Subscriber = []string{"browse", "reply", "post"}
+	l.Perms.Account = []string{"browse", "reply", "post"}
+	u.lists = append(u.lists, l)
+	return l
+}
+
+func (s *Server) findList(u *userState, name string) *lists.List {
+	for _, l := range u.lists {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+func (s *Server) serveLists(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	if parts[0] == "lists" {
+		return s.serveOwnLists(w, req, me, parts)
+	}
+	if parts[0] != "user" {
+		return false
+	}
+	if len(parts) <= 2 {
+		return s.serveUser(w, req, me, parts)
+	}
+	if req.Method != "GET" {
+		return false
+	}
+	u, ok := s.lookupUser(parts[1])
+	if !ok {
+		writeError(w, http.StatusNotFound, "", "No such user")
+		return true
+	}
+
+	switch {
+	case len(parts) == 3 && parts[2] == "lists":
+		writePage(w, req, s.pageSize, u.lists)
+	case len(parts) == 3 && parts[2] == "emails":
+		var sent []*lists.Post
+		for _, owner := range s.users {
+			for _, p := range owner.posts {
+				if p.Sender != nil && p.Sender.Name == u.user.Name {
+					sent = append(sent, p)
+				}
+			}
+		}
+		slices.SortFunc(sent, func(a, b *lists.Post) int { return cmp.Compare(a.ID, b.ID) })
+		writePage(w, req, s.pageSize, sent)
+	case len(parts) == 4 && parts[2] == "lists":
+		l := s.findList(u, parts[3])
+		if l == nil {
+			writeError(w, http.StatusNotFound, "", "No such list")
+			return true
+		}
+		writeJSON(w, http.StatusOK, l)
+	case len(parts) == 5 && parts[2] == "lists" && parts[4] == "posts":
+		if s.findList(u, parts[3]) == nil {
+			writeError(w, http.StatusNotFound, "", "No such list")
+			return true
+		}
+		var posts []*lists.Post
+		for _, p := range u.posts {
+			if p.List.Name == parts[3] {
+				posts = append(posts, p)
+			}
+		}
+		writePage(w, req, s.pageSize, posts)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveOwnLists(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	switch len(parts) {
+	case 1:
+		switch req.Method {
+		case "GET":
+			writePage(w, req, s.pageSize, me.lists)
+		case "POST":
+			var body struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			}
+			if !readJSON(w, req, &body) {
+				return true
+			}
+			if body.Name == "" {
+				writeError(w, http.StatusBadRequest, "name", "Name is required")
+				return true
+			}
+			if s.findList(me, body.Name) != nil {
+				writeError(w, http.StatusBadRequest, "name", "A list with this name already exists")
+				return true
+			}
+			writeJSON(w, http.StatusCreated, s.newList(me, body.Name, body.Description))
+		default:
+			return false
+		}
+	case 2:
+		l := s.findList(me, parts[1])
+		if l == nil {
+			writeError(w, http.StatusNotFound, "", "No such list")
+			return true
+		}
+		switch req.Method {
+		case "GET":
+			writeJSON(w, http.StatusOK, l)
+		case "DELETE":
+			me.lists, _ = remove(me.lists, func(v *lists.List) bool { return v == l })
+			writeNoContent(w)
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
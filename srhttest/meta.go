@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package srhttest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+)
+
+func (s *Server) serveMeta(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	if len(parts) < 2 || parts[0] != "user" {
+		return false
+	}
+	switch parts[1] {
+	case "profile":
+		return len(parts) == 2 && s.serveProfile(w, req, me)
+	case "audit-log":
+		if len(parts) != 2 || req.Method != "GET" {
+			return false
+		}
+		writePage(w, req, s.pageSize, me.auditLog)
+		return true
+	case "ssh-keys":
+		return s.serveSSHKeys(w, req, me, parts[2:])
+	case "pgp-keys":
+		return s.servePGPKeys(w, req, me, parts[2:])
+	case "webhooks":
+		return s.serveMetaWebhooks(w, req, me, parts[2:])
+	}
+	return false
+}
+
+func (s *Server) serveProfile(w http.ResponseWriter, req *http.Request, me *userState) bool {
+	switch req.Method {
+	case "GET":
+	case "PUT":
+		var params meta.ProfileParams
+		if !readJSON(w, req, &params) {
+			return true
+		}
+		for _, f := range []struct {
+			dst *string
+			src *string
+		}{
+			{&me.user.Email, params.Email},
+			{&me.user.URL, params.URL},
+			{&me.user.Location, params.Location},
+			{&me.user.Bio, params.Bio},
+		} {
+			if f.src != nil {
+				*f.dst = *f.src
+			}
+		}
+		s.audit(req, me, "profile:update", "Profile updated")
+	default:
+		return false
+	}
+	writeJSON(w, http.StatusOK, meta.User{User: me.user, UsePGPKey: me.usePGP})
+	return true
+}
+
+func (s *Server) serveSSHKeys(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	switch {
+	case len(parts) == 0 && req.Method == "GET":
+		writePage(w, req, s.pageSize, me.sshKeys)
+	case len(parts) == 0 && req.Method == "POST":
+		var body struct {
+			Key string `json:"ssh-key"`
+		}
+		if !readJSON(w, req, &body) {
+			return true
+		}
+		parsed, err := meta.ParseAuthorizedKey(body.Key)
+		if err != nil || len(parsed.Options) > 0 {
+			writeError(w, http.StatusBadRequest, "ssh-key", "Invalid SSH key")
+			return true
+		}
+		key := &meta.SSHKey{
+			ID:          s.id(),
+			Authorized:  s.now(),
+			Comment:     parsed.Comment,
+			Fingerprint: parsed.FingerprintMD5(),
+			Key:         body.Key,
+			Owner:       me.user.ShortUser,
+		}
+		me.sshKeys = append(me.sshKeys, key)
+		s.audit(req, me, "ssh-key:add", "SSH key "+key.Fingerprint+" added")
+		writeJSON(w, http.StatusCreated, key)
+	case len(parts) == 1:
+		id, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		match := func(k *meta.SSHKey) bool { return k.ID == id }
+		switch req.Method {
+		case "GET":
+			for _, k := range me.sshKeys {
+				if match(k) {
+					writeJSON(w, http.StatusOK, k)
+					return true
+				}
+			}
+		case "DELETE":
+			var ok bool
+			if me.sshKeys, ok = remove(me.sshKeys, match); ok {
+				s.audit(req, me, "ssh-key:remove", "SSH key "+parts[0]+" removed")
+				writeNoContent(w)
+				return true
+			}
+		default:
+			return false
+		}
+		writeError(w, http.StatusNotFound, "", "No such SSH key")
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) servePGPKeys(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	switch {
+	case len(parts) == 0 && req.Method == "GET":
+		writePage(w, req, s.pageSize, me.pgpKeys)
+	case len(parts) == 0 && req.Method == "POST":
+		var body struct {
+			Key string `json:"pgp-key"`
+		}
+		if !readJSON(w, req, &body) {
+			return true
+		}
+		if strings.TrimSpace(body.Key) == "" {
+			writeError(w, http.StatusBadRequest, "pgp-key", "Invalid PGP key")
+			return true
+		}
+		sum := sha256.Sum256([]byte(body.Key))
+		key := &meta.PGPKey{
+			ID:         s.id(),
+			Authorized: s.now(),
+			Email:      me.user.Email,
+			KeyID:      strings.ToUpper(hex.EncodeToString(sum[:8])),
+			Key:        body.Key,
+			Owner:      me.user.ShortUser,
+		}
+		me.pgpKeys = append(me.pgpKeys, key)
+		s.audit(req, me, "pgp-key:add", "PGP key "+key.KeyID+" added")
+		writeJSON(w, http.StatusCreated, key)
+	case len(parts) == 1:
+		id, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		match := func(k *meta.PGPKey) bool { return k.ID == id }
+		switch req.Method {
+		case "GET":
+			for _, k := range me.pgpKeys {
+				if match(k) {
+					writeJSON(w, http.StatusOK, k)
+					return true
+				}
+			}
+		case "DELETE":
+			var ok bool
+			if me.pgpKeys, ok = remove(me.pgpKeys, match); ok {
+				s.audit(req, me, "pgp-key:remove", "PGP key "+parts[0]+" removed")
+				writeNoContent(w)
+				return true
+			}
+		default:
+			return false
+		}
+		writeError(w, http.StatusNotFound, "", "No such PGP key")
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveMetaWebhooks(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	switch {
+	case len(parts) == 0 && req.Method == "GET":
+		writePage(w, req, s.pageSize, me.webhooks)
+	case len(parts) == 0 && req.Method == "POST":
+		var body struct {
+			URL    string       `json:"url"`
+			Events []meta.Event `json:"events"`
+		}
+		if !readJSON(w, req, &body) {
+			return true
+		}
+		if u, err := url.Parse(body.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			writeError(w, http.StatusBadRequest, "url", "Invalid URL")
+			return true
+		}
+		for _, e := range body.Events {
+			switch e {
+			case meta.EventProfileUpdate, meta.EventPGPKeyAdd, meta.EventPGPKeyRemove, meta.EventSSHKeyAdd, meta.EventSSHKeyRemove:
+			default:
+				writeError(w, http.StatusBadRequest, "events", "Unsupported event "+string(e))
+				return true
+			}
+		}
+		hook := &meta.Webhook{
+			ID:      s.id(),
+			Created: s.now(),
+			Events:  body.Events,
+			URL:     body.URL,
+		}
+		me.webhooks = append(me.webhooks, hook)
+		writeJSON(w, http.StatusCreated, hook)
+	case len(parts) == 1:
+		id, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		match := func(h *meta.Webhook) bool { return h.ID == id }
+		switch req.Method {
+		case "GET":
+			for _, h := range me.webhooks {
+				if match(h) {
+					writeJSON(w, http.StatusOK, h)
+					return true
+				}
+			}
+		case "DELETE":
+			var ok bool
+			if me.webhooks, ok = remove(me.webhooks, match); ok {
+				writeNoContent(w)
+				return true
+			}
+		default:
+			return false
+		}
+		writeError(w, http.StatusNotFound, "", "No such webhook")
+	default:
+		return false
+	}
+	return true
+}
+
+// audit records an entry in the users audit log.
+func (s *Server) audit(req *http.Request, u *userState, action, details string) {
+	ip, _, _ := strings.Cut(req.RemoteAddr, ":")
+	u.auditLog = append(u.auditLog, &meta.AuditLog{
+		ID:      s.id(),
+		IP:      ip,
+		Action:  action,
+		Details: details,
+		Created: s.now(),
+	})
+}
+
+// shortUser returns the short form of the named user, creating it if it does
+// not exist.
+func (s *Server) shortUser(name string) (*userState, sourcehut.ShortUser) {
+	name = strings.TrimPrefix(name, "~")
+	u := s.addUser(name, fmt.Sprintf("%s@example.org", name))
+	return u, u.user.ShortUser
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package srhttest
+
+import (
+	/* #nosec */
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~wombelix/sourcehut-go/paste"
+)
+
+func (s *Server) servePaste(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	switch {
+	case len(parts) == 1 && parts[0] == "pastes":
+		switch req.Method {
+		case "GET":
+			writePage(w, req, s.pageSize, me.pastes)
+		case "POST":
+			var body struct {
+				Files paste.Files `json:"files"`
+			}
+			if !readJSON(w, req, &body) {
+				return true
+			}
+			if len(body.Files) == 0 {
+				writeError(w, http.StatusBadRequest, "files", "At least one file is required")
+				return true
+			}
+			p := &paste.Paste{
+				Created: s.now(),
+				User:    me.user.ShortUser,
+			}
+			/* #nosec */
+			h := sha1.New()
+			/* #nosec */
+			_, _ = h.Write([]byte(strconv.FormatInt(s.id(), 10)))
+			for _, f := range body.Files {
+				/* #nosec */
+				sum := sha1.Sum([]byte(f.Contents))
+				id := hex.EncodeToString(sum[:])
+				s.blobs[id] = &paste.Blob{ID: id, Created: p.Created, Contents: f.Contents}
+				p.Files = append(p.Files, struct {
+					ID   string `json:"blob_id"`
+					Name string `json:"filename"`
+				}{ID: id, Name: f.Name})
+				/* #nosec */
+				_, _ = h.Write([]byte("\x00" + f.Name + "\x00" + id))
+			}
+			p.ID = hex.EncodeToString(h.Sum(nil))
+			me.pastes = append(me.pastes, p)
+			writeJSON(w, http.StatusCreated, p)
+		default:
+			return false
+		}
+	case len(parts) == 2 && parts[0] == "pastes":
+		match := func(p *paste.Paste) bool { return p.ID == parts[1] }
+		switch req.Method {
+		case "GET":
+			for _, p := range me.pastes {
+				if match(p) {
+					writeJSON(w, http.StatusOK, p)
+					return true
+				}
+			}
+		case "DELETE":
+			var ok bool
+			if me.pastes, ok = remove(me.pastes, match); ok {
+				writeNoContent(w)
+				return true
+			}
+		default:
+			return false
+		}
+		writeError(w, http.StatusNotFound, "", "No such paste")
+	case len(parts) == 2 && parts[0] == "blobs" && req.Method == "GET":
+		b, ok := s.blobs[parts[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "", "No such blob")
+			return true
+		}
+		writeJSON(w, http.StatusOK, b)
+	default:
+		return false
+	}
+	return true
+}
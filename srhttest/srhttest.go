@@ -0,0 +1,388 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package srhttest provides an in-memory fake of the Sourcehut API for use in
+// tests.
+//
+// A single Server fakes the meta, git, todo, lists, and paste services.
+// It keeps state between requests, so resources that are created through the
+// API can be listed, fetched, and deleted again.
+// List endpoints are paginated and errors are returned using the same
+// envelope as the real API.
+// The meta GraphQL API is only faked as far as it is needed to manage personal
+// access tokens and OAuth applications.
+//
+// Each service client is pointed at the fake using its Base option:
+//
+//	srv := srhttest.NewServer()
+//	defer srv.Close()
+//
+//	gitClient, err := git.NewClient(
+//		git.SrhtClient(srv.Client()),
+//		git.Base(srv.URL(srhttest.Git)),
+//	)
+package srhttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+	"git.sr.ht/~wombelix/sourcehut-go/lists"
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+	"git.sr.ht/~wombelix/sourcehut-go/paste"
+	"git.sr.ht/~wombelix/sourcehut-go/todo"
+)
+
+// Services supported by the fake server.
+const (
+	Meta  = "meta"
+	Git   = "git"
+	Todo  = "todo"
+	Lists = "lists"
+	Paste = "paste"
+)
+
+// Token is the access token accepted by the fake server, along with personal
+// access tokens issued through the meta GraphQL API.
+// Requests that use a different token are rejected with 401 Unauthorized, as
+// are requests without a token unless they only read public data.
+const Token = "srhttest-token"
+
+// Username is the name of the authenticated user.
+const Username = "test"
+
+// Version is the API version reported by every service.
+const Version = "0.0.0"
+
+// DefaultPageSize is the number of results per page used by list endpoints
+// unless PageSize is changed.
+const DefaultPageSize = 50
+
+// Server is a fake Sourcehut instance.
+// It is safe for concurrent use.
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	pageSize int
+	nextID   int64
+	users    map[string]*userState
+	blobs    map[string]*paste.Blob
+	secrets  map[string]int64
+}
+
+// userState is all of the data owned by a single user.
+type userState struct {
+	user     sourcehut.User
+	usePGP   string
+	sshKeys  []*meta.SSHKey
+	pgpKeys  []*meta.PGPKey
+	auditLog []*meta.AuditLog
+	tokens   []*meta.PersonalAccessToken
+	grants   []*meta.OAuthGrant
+	clients  []*meta.OAuthClient
+	webhooks []*meta.Webhook
+	repos    []*git.Repo
+	trackers []*todo.Tracker
+	lists    []*lists.List
+	posts    []*lists.Post
+	pastes   []*paste.Paste
+}
+
+// NewServer starts and returns a new fake server.
+// The caller should call Close when finished to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		pageSize: DefaultPageSize,
+		users:    make(map[string]*userState),
+		blobs:    make(map[string]*paste.Blob),
+		secrets:  make(map[string]int64),
+	}
+	s.addUser(Username, Username+"@example.org")
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// URL returns the base API URL of the provided service (eg. srhttest.Git),
+// suitable for use with the service client's Base option.
+func (s *Server) URL(service string) string {
+	return s.srv.URL + "/" + service + "/api/"
+}
+
+// Instance returns a sourcehut.Instance that uses Client and the base URLs of
+// the services provided by the server.
+func (s *Server) Instance() *sourcehut.Instance {
+	opts := make([]sourcehut.InstanceOption, 0, len(sourcehut.Services))
+	for _, service := range sourcehut.Services {
+		opts = append(opts, sourcehut.ServiceBase(service, s.URL(service)))
+	}
+	inst, err := sourcehut.NewInstance(s.srv.URL, s.Client(), opts...)
+	if err != nil {
+		// The URL of a running httptest.Server is always valid.
+		panic(err)
+	}
+	return inst
+}
+
+// Client returns a sourcehut.Client that is authenticated with the server.
+func (s *Server) Client() sourcehut.Client {
+	return sourcehut.NewClient(
+		sourcehut.Token(Token),
+		sourcehut.UserAgent("srhttest"),
+		sourcehut.RoundTripper(s.srv.Client().Transport),
+	)
+}
+
+// HTTPClient returns an http.Client that is configured to talk to the server
+// without adding any authentication.
+func (s *Server) HTTPClient() *http.Client {
+	return s.srv.Client()
+}
+
+// SetPageSize changes the number of results returned per page by all list
+// endpoints.
+func (s *Server) SetPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n < 1 {
+		n = DefaultPageSize
+	}
+	s.pageSize = n
+}
+
+// AddUser creates a user that can own resources and be looked up by name.
+// If the user already exists it is returned unchanged.
+func (s *Server) AddUser(name, email string) sourcehut.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(strings.TrimPrefix(name, "~"), email).user
+}
+
+func (s *Server) addUser(name, email string) *userState {
+	if u, ok := s.users[name]; ok {
+		return u
+	}
+	u := &userState{
+		user: sourcehut.User{
+			ShortUser: sourcehut.ShortUser{
+				CanonicalName: "~" + name,
+				Name:          name,
+			},
+			Email: email,
+		},
+	}
+	s.users[name] = u
+	return u
+}
+
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// lookupUser returns the state of the provided user (with or without a leading
+// "~").
+func (s *Server) lookupUser(name string) (*userState, bool) {
+	u, ok := s.users[strings.TrimPrefix(name, "~")]
+	return u, ok
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	var anonymous bool
+	switch auth := req.Header.Get("Authorization"); {
+	case auth == "token "+Token, auth == "Bearer "+Token, s.issued(auth):
+	case auth == "":
+		anonymous = true
+	default:
+		writeError(w, http.StatusUnauthorized, "", "Invalid or missing access token")
+		return
+	}
+
+	if req.URL.Path == "/"+Meta+"/query" {
+		if anonymous {
+			writeError(w, http.StatusUnauthorized, "", "Invalid or missing access token")
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.serveMetaGraphQL(w, req, s.users[Username])
+		return
+	}
+
+	service, p, ok := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/api/")
+	if !ok {
+		writeError(w, http.StatusNotFound, "", "Not found")
+		return
+	}
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	if anonymous && (req.Method != "GET" || !isPublic(service, parts)) {
+		writeError(w, http.StatusUnauthorized, "", "Invalid or missing access token")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(parts) == 1 && parts[0] == "version" {
+		writeJSON(w, http.StatusOK, struct {
+			Version string `json:"version"`
+		}{Version: Version})
+		return
+	}
+	me := s.users[Username]
+
+	var handled bool
+	switch service {
+	case Meta:
+		handled = s.serveMeta(w, req, me, parts)
+	case Git:
+		handled = s.serveGit(w, req, me, parts)
+	case Todo:
+		handled = s.serveTodo(w, req, me, parts)
+	case Lists:
+		handled = s.serveLists(w, req, me, parts)
+	case Paste:
+		handled = s.servePaste(w, req, me, parts)
+	}
+	if !handled {
+		writeError(w, http.StatusNotFound, "", "Not found")
+	}
+}
+
+// isPublic reports whether the path can be read without authentication, which
+// is the case for the API version and for resources that are looked up by their
+// owner's username or by ID rather than belonging to the authenticated user.
+func isPublic(service string, parts []string) bool {
+	if len(parts) == 1 && parts[0] == "version" {
+		return true
+	}
+	switch service {
+	case Git:
+		if parts[0] == "user" {
+			return len(parts) == 2
+		}
+		return parts[0] != "repos"
+	case Todo, Lists:
+		return parts[0] == "user" && len(parts) >= 2
+	case Paste:
+		return len(parts) == 2 && (parts[0] == "pastes" || parts[0] == "blobs")
+	}
+	return false
+}
+
+// serveUser handles the user lookup endpoint shared by several services.
+func (s *Server) serveUser(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	if req.Method != "GET" {
+		return false
+	}
+	switch len(parts) {
+	case 1:
+		writeJSON(w, http.StatusOK, me.user)
+	case 2:
+		u, ok := s.lookupUser(parts[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, "", "No such user")
+			return true
+		}
+		writeJSON(w, http.StatusOK, u.user)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// writePage writes the page of items requested by the "start" query parameter.
+func writePage[T any](w http.ResponseWriter, req *http.Request, pageSize int, items []T) {
+	start := 0
+	if v := req.URL.Query().Get("start"); v != "" {
+		var err error
+		start, err = strconv.Atoi(v)
+		if err != nil || start < 0 || start > len(items) {
+			writeError(w, http.StatusBadRequest, "start", "Invalid start")
+			return
+		}
+	}
+	end := start + pageSize
+	var next *string
+	if end < len(items) {
+		n := strconv.Itoa(end)
+		next = &n
+	} else {
+		end = len(items)
+	}
+	results := items[start:end]
+	if results == nil {
+		results = []T{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Next           *string `json:"next"`
+		Results        []T     `json:"results"`
+		ResultsPerPage int     `json:"results_per_page"`
+		Total          int     `json:"total"`
+	}{
+		Next:           next,
+		Results:        results,
+		ResultsPerPage: pageSize,
+		Total:          len(items),
+	})
+}
+
+// readJSON decodes the request body into v and writes an error if that is not
+// possible.
+func readJSON(w http.ResponseWriter, req *http.Request, v interface{}) bool {
+	err := json.NewDecoder(req.Body).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", "Invalid JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	/* #nosec */
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, field, reason string) {
+	type apiError struct {
+		Field  string `json:"field,omitempty"`
+		Reason string `json:"reason"`
+	}
+	writeJSON(w, code, struct {
+		Errors []apiError `json:"errors"`
+	}{
+		Errors: []apiError{{Field: field, Reason: reason}},
+	})
+}
+
+func writeNoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// remove deletes the first element of s for which match returns true.
+func remove[T any](s []T, match func(T) bool) ([]T, bool) {
+	for i, v := range s {
+		if match(v) {
+			return append(s[:i], s[i+1:]...), true
+		}
+	}
+	return s, false
+}
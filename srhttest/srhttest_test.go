@@ -0,0 +1,524 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package srhttest_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+	"git.sr.ht/~wombelix/sourcehut-go/lists"
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+	"git.sr.ht/~wombelix/sourcehut-go/paste"
+	"git.sr.ht/~wombelix/sourcehut-go/srhttest"
+	"git.sr.ht/~wombelix/sourcehut-go/todo"
+)
+
+const testSSHKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICf9nXGV4cuw2QVU+d7RpX+a3L3J03Eh/MdAxop0cecR test@example"
+
+func newServer(t *testing.T) *srhttest.Server {
+	t.Helper()
+	srv := srhttest.NewServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGit(t *testing.T) {
+	srv := newServer(t)
+	srv.SetPageSize(2)
+	ctx := context.Background()
+	client, err := git.NewClient(git.SrhtClient(srv.Client()), git.Base(srv.URL(srhttest.Git)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ver, err := client.Version(ctx)
+	if err != nil || ver != srhttest.Version {
+		t.Fatalf("Unexpected version %q: %v", ver, err)
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		_, err = client.NewRepo(ctx, name, "repo "+name, git.VisibilityPublic)
+		if err != nil {
+			t.Fatalf("Error creating repo %s: %v", name, err)
+		}
+	}
+	_, err = client.NewRepo(ctx, "a", "", git.VisibilityPublic)
+	if !sourcehut.IsValidation(err) {
+		t.Errorf("Expected validation error creating duplicate repo, got: %v", err)
+	}
+
+	repos, err := client.Repos(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for repo, err := range repos.All() {
+		if err != nil {
+			t.Fatalf("Error listing repos: %v", err)
+		}
+		names = append(names, repo.Name)
+	}
+	if len(names) != 3 || names[2] != "c" {
+		t.Errorf("Unexpected repos across pages: %v", names)
+	}
+
+	repo, err := client.Repo(ctx, "", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.Description = "updated"
+	repo.Visibility = git.VisibilityPrivate
+	err = client.UpdateRepo(ctx, "b", repo)
+	if err != nil {
+		t.Fatalf("Error updating repo: %v", err)
+	}
+	repo, err = client.Repo(ctx, "", "b")
+	if err != nil || repo.Description != "updated" || repo.Visibility != git.VisibilityPrivate {
+		t.Fatalf("Update was not applied: %+v, %v", repo, err)
+	}
+
+	err = client.DeleteRepo(ctx, "a")
+	if err != nil {
+		t.Fatalf("Error deleting repo: %v", err)
+	}
+	_, err = client.Repo(ctx, "", "a")
+	if !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error after delete, got: %v", err)
+	}
+
+	srv.AddRepo("other", "public", "", git.VisibilityPublic)
+	srv.AddRepo("other", "secret", "", git.VisibilityPrivate)
+	repos, err = client.Repos(ctx, "~other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names = nil
+	for repo, err := range repos.All() {
+		if err != nil {
+			t.Fatalf("Error listing repos: %v", err)
+		}
+		names = append(names, repo.Name)
+	}
+	if len(names) != 1 || names[0] != "public" {
+		t.Errorf("Expected only public repos of another user, got: %v", names)
+	}
+	_, err = client.Repo(ctx, "~other", "secret")
+	if !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected private repo of another user to be hidden, got: %v", err)
+	}
+}
+
+func TestMeta(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	client, err := meta.NewClient(meta.SrhtClient(srv.Client()), meta.Base(srv.URL(srhttest.Meta)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bio := "Hello"
+	user, err := client.UpdateUser(ctx, meta.ProfileParams{Bio: &bio})
+	if err != nil || user.Bio != bio || user.Name != srhttest.Username {
+		t.Fatalf("Unexpected profile %+v: %v", user, err)
+	}
+
+	key, err := client.NewSSHKey(ctx, testSSHKey)
+	if err != nil {
+		t.Fatalf("Error adding SSH key: %v", err)
+	}
+	if key.Comment != "test@example" || key.Fingerprint == "" {
+		t.Errorf("Unexpected SSH key: %+v", key)
+	}
+	_, err = client.NewSSHKey(ctx, "garbage")
+	var srhtErr sourcehut.Error
+	if !errors.As(err, &srhtErr) || srhtErr.Field != "ssh-key" {
+		t.Errorf("Expected field error for invalid key, got: %v", err)
+	}
+	got, err := client.GetSSHKey(ctx, key.ID)
+	if err != nil || got.Key != testSSHKey {
+		t.Fatalf("Unexpected SSH key %+v: %v", got, err)
+	}
+	local, err := meta.ParseAuthorizedKey(`no-pty ` + testSSHKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, ok, err := client.FindSSHKey(ctx, local)
+	if err != nil || !ok || found.ID != key.ID {
+		t.Errorf("Expected to find registered key, got %+v, %t: %v", found, ok, err)
+	}
+	err = client.DeleteSSHKey(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Error deleting SSH key: %v", err)
+	}
+	_, err = client.GetSSHKey(ctx, key.ID)
+	if !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error after delete, got: %v", err)
+	}
+	if _, ok, err = client.FindSSHKey(ctx, local); err != nil || ok {
+		t.Errorf("Expected deleted key not to be found, got %t: %v", ok, err)
+	}
+
+	logs, err := client.ListAuditLog(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for entry, err := range logs.All() {
+		if err != nil {
+			t.Fatalf("Error listing audit log: %v", err)
+		}
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 3 {
+		t.Errorf("Unexpected audit log: %v", actions)
+	}
+}
+
+func TestMetaTokens(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	client, err := meta.NewClient(meta.SrhtClient(srv.Client()), meta.Base(srv.URL(srhttest.Meta)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tok, secret, err := client.NewToken(ctx, "CI", "meta.sr.ht/PROFILE:RO", "git.sr.ht/REPOSITORIES:RW")
+	if err != nil {
+		t.Fatalf("Error issuing token: %v", err)
+	}
+	if secret == "" || tok.Comment != "CI" || !reflect.DeepEqual(tok.Scopes(), []string{"meta.sr.ht/PROFILE:RO", "git.sr.ht/REPOSITORIES:RW"}) {
+		t.Errorf("Unexpected token %+v with secret %q", tok, secret)
+	}
+
+	// The new token can be used to rotate itself.
+	ci, err := meta.NewClient(
+		meta.SrhtClient(sourcehut.NewClient(
+			sourcehut.Token(secret),
+			sourcehut.UserAgent("test"),
+			sourcehut.RoundTripper(srv.HTTPClient().Transport),
+		)),
+		meta.Base(srv.URL(srhttest.Meta)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, _, err := ci.NewToken(ctx, "CI (rotated)")
+	if err != nil {
+		t.Fatalf("Error issuing token with issued token: %v", err)
+	}
+	if err = ci.RevokeToken(ctx, tok.ID); err != nil {
+		t.Fatalf("Error revoking token: %v", err)
+	}
+	if _, err = ci.GetUser(ctx); !sourcehut.IsUnauthorized(err) {
+		t.Errorf("Expected unauthorized error using revoked token, got: %v", err)
+	}
+	tokens, err := client.ListTokens(ctx)
+	if err != nil || len(tokens) != 1 || tokens[0].ID != next.ID {
+		t.Errorf("Unexpected tokens %+v: %v", tokens, err)
+	}
+	if err = client.RevokeToken(ctx, tok.ID); !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error revoking token twice, got: %v", err)
+	}
+}
+
+func TestMetaOAuth(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	client, err := meta.NewClient(meta.SrhtClient(srv.Client()), meta.Base(srv.URL(srhttest.Meta)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, secret, err := client.RegisterOAuthClient(ctx, meta.OAuthClientParams{
+		Name:        "Example",
+		RedirectURL: "https://example.org/callback",
+	})
+	if err != nil || secret == "" || app.UUID == "" || app.Name != "Example" {
+		t.Fatalf("Unexpected client %+v with secret %q: %v", app, secret, err)
+	}
+	_, _, err = client.RegisterOAuthClient(ctx, meta.OAuthClientParams{Name: "No redirect"})
+	var srhtErr sourcehut.Error
+	if !errors.As(err, &srhtErr) || srhtErr.Field == "" {
+		t.Errorf("Expected field error registering client without redirect URL, got: %v", err)
+	}
+	apps, err := client.ListOAuthClients(ctx)
+	if err != nil || len(apps) != 1 || apps[0] != app {
+		t.Errorf("Unexpected clients %+v: %v", apps, err)
+	}
+	if err = client.DeleteOAuthClient(ctx, app.UUID); err != nil {
+		t.Fatalf("Error deleting client: %v", err)
+	}
+	if err = client.DeleteOAuthClient(ctx, app.UUID); !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error deleting client twice, got: %v", err)
+	}
+
+	grant := srv.AddOAuthGrant("Third party")
+	grants, err := client.ListOAuthGrants(ctx)
+	if err != nil || len(grants) != 1 || grants[0].Client.Name != "Third party" || grants[0].TokenHash != grant.TokenHash {
+		t.Fatalf("Unexpected grants %+v: %v", grants, err)
+	}
+	if err = client.RevokeOAuthGrant(ctx, grant.TokenHash); err != nil {
+		t.Fatalf("Error revoking grant: %v", err)
+	}
+	if grants, err = client.ListOAuthGrants(ctx); err != nil || len(grants) != 0 {
+		t.Errorf("Expected no grants after revoking, got %+v: %v", grants, err)
+	}
+}
+func TestMetaWebhooks(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	client, err := meta.NewClient(meta.SrhtClient(srv.Client()), meta.Base(srv.URL(srhttest.Meta)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook, err := client.NewWebhook(ctx, "https://example.org/hook", meta.EventSSHKeyAdd, meta.EventSSHKeyRemove)
+	if err != nil {
+		t.Fatalf("Error creating webhook: %v", err)
+	}
+	if hook.URL != "https://example.org/hook" || len(hook.Events) != 2 {
+		t.Errorf("Unexpected webhook: %+v", hook)
+	}
+	_, err = client.NewWebhook(ctx, "https://example.org/hook", "repo:update")
+	if !sourcehut.IsValidation(err) {
+		t.Errorf("Expected validation error for unsupported event, got: %v", err)
+	}
+	got, err := client.GetWebhook(ctx, hook.ID)
+	if err != nil || got.URL != hook.URL {
+		t.Fatalf("Unexpected webhook %+v: %v", got, err)
+	}
+	hooks, err := client.ListWebhooks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hooks.Next() || hooks.Webhook().ID != hook.ID || hooks.Next() {
+		t.Errorf("Expected a single webhook, got: %v", hooks.Err())
+	}
+	if err = client.DeleteWebhook(ctx, hook.ID); err != nil {
+		t.Fatalf("Error deleting webhook: %v", err)
+	}
+	if _, err = client.GetWebhook(ctx, hook.ID); !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error after delete, got: %v", err)
+	}
+}
+
+func TestTodo(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	client, err := todo.NewClient(todo.SrhtClient(srv.Client()), todo.Base(srv.URL(srhttest.Todo)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.NewTracker(ctx, "bugs", "Bug reports")
+	if err != nil {
+		t.Fatalf("Error creating tracker: %v", err)
+	}
+	tracker, err := client.Tracker(ctx, "", "bugs")
+	if err != nil || tracker.Desc != "Bug reports" || tracker.Owner.Name != srhttest.Username {
+		t.Fatalf("Unexpected tracker %+v: %v", tracker, err)
+	}
+
+	srv.AddTracker("other", "features", "")
+	trackers, err := client.Trackers(ctx, "~other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for _, err := range trackers.All() {
+		if err != nil {
+			t.Fatalf("Error listing trackers: %v", err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 tracker, got %d", n)
+	}
+	_, err = client.Tracker(ctx, "~nobody", "bugs")
+	if !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error for unknown user, got: %v", err)
+	}
+}
+
+func TestLists(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	client, err := lists.NewClient(lists.SrhtClient(srv.Client()), lists.Base(srv.URL(srhttest.Lists)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.AddPost("~owner", "devel", "~sender", "First", "From: sender")
+	srv.AddPost("~owner", "devel", "~test", "Second", "From: test")
+	srv.AddPost("~owner", "announce", "~sender", "Third", "From: sender")
+
+	ls, err := client.List(ctx, "~owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for l, err := range ls.All() {
+		if err != nil {
+			t.Fatalf("Error listing lists: %v", err)
+		}
+		names = append(names, l.Name)
+	}
+	if len(names) != 2 {
+		t.Errorf("Unexpected lists: %v", names)
+	}
+
+	posts, err := client.ListPosts(ctx, "~owner", "devel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subjects []string
+	for p, err := range posts.All() {
+		if err != nil {
+			t.Fatalf("Error listing posts: %v", err)
+		}
+		subjects = append(subjects, p.Subject)
+	}
+	if len(subjects) != 2 || subjects[0] != "First" {
+		t.Errorf("Unexpected posts: %v", subjects)
+	}
+
+	emails, err := client.ListEmails(ctx, "~sender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subjects = nil
+	for p, err := range emails.All() {
+		if err != nil {
+			t.Fatalf("Error listing emails: %v", err)
+		}
+		subjects = append(subjects, p.Subject)
+	}
+	if len(subjects) != 2 || subjects[0] != "First" || subjects[1] != "Third" {
+		t.Errorf("Unexpected emails: %v", subjects)
+	}
+
+	posts, err = client.ListPosts(ctx, "~owner", "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts.Next() || !sourcehut.IsNotFound(posts.Err()) {
+		t.Errorf("Expected not found error for unknown list, got: %v", posts.Err())
+	}
+}
+
+func TestPaste(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	client, err := paste.NewClient(paste.SrhtClient(srv.Client()), paste.Base(srv.URL(srhttest.Paste)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := client.New(ctx, paste.Files{{Name: "hello.txt", Contents: "Hello, world!"}})
+	if err != nil {
+		t.Fatalf("Error creating paste: %v", err)
+	}
+	if len(p.Files) != 1 || p.Files[0].Name != "hello.txt" {
+		t.Fatalf("Unexpected paste: %+v", p)
+	}
+	got, err := client.Get(ctx, p.ID)
+	if err != nil || got.ID != p.ID {
+		t.Fatalf("Unexpected paste %+v: %v", got, err)
+	}
+	blob, err := client.GetBlob(ctx, p.Files[0].ID)
+	if err != nil || blob.Contents != "Hello, world!" {
+		t.Fatalf("Unexpected blob %+v: %v", blob, err)
+	}
+	_, err = client.New(ctx, nil)
+	if !sourcehut.IsValidation(err) {
+		t.Errorf("Expected validation error for empty paste, got: %v", err)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := newServer(t)
+	client, err := git.NewClient(
+		git.SrhtClient(sourcehut.NewClient(
+			sourcehut.Token("wrong"),
+			sourcehut.UserAgent("test"),
+			sourcehut.RoundTripper(srv.HTTPClient().Transport),
+		)),
+		git.Base(srv.URL(srhttest.Git)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos, err := client.Repos(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repos.Next() || !sourcehut.IsUnauthorized(repos.Err()) {
+		t.Errorf("Expected unauthorized error, got: %v", repos.Err())
+	}
+}
+
+func TestAnonymous(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	client, err := git.NewClient(git.SrhtClient(srv.Client()), git.Base(srv.URL(srhttest.Git)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.NewRepo(ctx, "public", "", git.VisibilityPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anon, err := git.NewClient(
+		git.SrhtClient(sourcehut.NewClient(
+			sourcehut.Anonymous(),
+			sourcehut.UserAgent("test"),
+			sourcehut.RoundTripper(srv.HTTPClient().Transport),
+		)),
+		git.Base(srv.URL(srhttest.Git)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := anon.Repo(ctx, "~"+srhttest.Username, "public")
+	if err != nil || repo.Name != "public" {
+		t.Fatalf("Unexpected repo %+v: %v", repo, err)
+	}
+	repos, err := anon.Repos(ctx, srhttest.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repos.Next() || repos.Current().Name != "public" {
+		t.Errorf("Expected public repo, got: %v", repos.Err())
+	}
+
+	// Calls that need authentication must fail before a request is made, so the
+	// error is not one returned by the server.
+	var authErr *sourcehut.AuthRequiredError
+	_, err = anon.Repos(ctx, "")
+	if !errors.As(err, &authErr) || authErr.Endpoint != "repos" {
+		t.Errorf("Expected auth required error listing own repos, got: %v", err)
+	}
+	_, err = anon.NewRepo(ctx, "new", "", git.VisibilityPublic)
+	if !errors.Is(err, sourcehut.ErrAuthRequired) {
+		t.Errorf("Expected auth required error creating repo, got: %v", err)
+	}
+
+	anonMeta, err := meta.NewClient(
+		meta.SrhtClient(sourcehut.NewClient(sourcehut.Anonymous(), sourcehut.UserAgent("test"))),
+		meta.Base(srv.URL(srhttest.Meta)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = anonMeta.GetUser(ctx)
+	if !errors.As(err, &authErr) || authErr.Service != sourcehut.ServiceMeta {
+		t.Errorf("Expected auth required error from meta, got: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package srhttest
+
+import (
+	"net/http"
+
+	"git.sr.ht/~wombelix/sourcehut-go/todo"
+)
+
+// AddTracker creates an issue tracker owned by the named user, creating the
+// user if it does not exist.
+func (s *Server) AddTracker(owner, name, description string) todo.Tracker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, _ := s.shortUser(owner)
+	return *s.newTracker(u, name, description)
+}
+
+func (s *Server) newTracker(u *userState, name, description string) *todo.Tracker {
+	t := &todo.Tracker{
+		ShortTracker: todo.ShortTracker{
+			Name:    name,
+			Owner:   u.user.ShortUser,
+			Created: s.now(),
+			Updated: s.now(),
+		},
+		Desc: description,
+	}
+	t.Perms.Anonymous = []string{"browse"}
+	t.Perms.Submitter = []string{"browse", "submit", "comment"}
+	t.Perms.User = []string{"browse", "submit", "comment"}
+	u.trackers = append(u.trackers, t)
+	return t
+}
+
+func (s *Server) serveTodo(w http.ResponseWriter, req *http.Request, me *userState, parts []string) bool {
+	owner := me
+	switch {
+	case parts[0] == "user" && len(parts) >= 3 && parts[2] == "trackers":
+		// user/~username/trackers[/name]
+		if req.Method != "GET" {
+			return false
+		}
+		u, ok := s.lookupUser(parts[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, "", "No such user")
+			return true
+		}
+		owner = u
+		parts = parts[2:]
+	case parts[0] == "user":
+		return s.serveUser(w, req, me, parts)
+	case parts[0] != "trackers":
+		return false
+	}
+
+	switch len(parts) {
+	case 1:
+		switch req.Method {
+		case "GET":
+			writePage(w, req, s.pageSize, owner.trackers)
+		case "POST":
+			var body struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			}
+			if !readJSON(w, req, &body) {
+				return true
+			}
+			if body.Name == "" {
+				writeError(w, http.StatusBadRequest, "name", "Name is required")
+				return true
+			}
+			if s.findTracker(owner, body.Name) != nil {
+				writeError(w, http.StatusBadRequest, "name", "A tracker with this name already exists")
+				return true
+			}
+			writeJSON(w, http.StatusCreated, s.newTracker(owner, body.Name, body.Description))
+		default:
+			return false
+		}
+	case 2:
+		t := s.findTracker(owner, parts[1])
+		if t == nil {
+			writeError(w, http.StatusNotFound, "", "No such tracker")
+			return true
+		}
+		switch req.Method {
+		case "GET":
+			writeJSON(w, http.StatusOK, t)
+		case "DELETE":
+			owner.trackers, _ = remove(owner.trackers, func(v *todo.Tracker) bool { return v == t })
+			writeNoContent(w)
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) findTracker(u *userState, name string) *todo.Tracker {
+	for _, t := range u.trackers {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package todo_test
+
+import (
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/contract"
+	"git.sr.ht/~wombelix/sourcehut-go/todo"
+)
+
+func TestContract(t *testing.T) {
+	contract.Check(t, "testdata/contract.json", map[string]func() interface{}{
+		"ShortTracker": func() interface{} { return &todo.ShortTracker{} },
+		"Tracker":      func() interface{} { return &todo.Tracker{} },
+	})
+}
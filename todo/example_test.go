@@ -5,6 +5,7 @@
 package todo_test
 
 import (
+	"context"
 	"log"
 
 	"git.sr.ht/~wombelix/sourcehut-go"
@@ -15,7 +16,7 @@ func ExampleTrackerIter() {
 	srhtClient := sourcehut.NewClient(sourcehut.Token("<personal access token>"))
 	todoClient, _ := todo.NewClient(todo.SrhtClient(srhtClient))
 
-	iter, _ := todoClient.Trackers("~sircmpwn")
+	iter, _ := todoClient.Trackers(context.Background(), "~sircmpwn")
 	for iter.Next() {
 		p := iter.Tracker()
 		log.Printf("Tracker %s: %s\n", p.Name, p.Desc)
@@ -10,11 +10,11 @@ import (
 
 // TrackerIter is used for iterating over a collection of issue trackers.
 type TrackerIter struct {
-	*sourcehut.Iter
+	*sourcehut.Iter[*Tracker]
 }
 
 // Tracker returns the issue tracker which the iterator is currently pointing
 // to.
 func (i TrackerIter) Tracker() *Tracker {
-	return i.Current().(*Tracker)
+	return i.Current()
 }
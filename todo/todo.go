@@ -7,6 +7,7 @@ package todo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -21,6 +22,18 @@ import (
 // It is exported for convenience.
 const BaseURL = "https://todo.sr.ht/api/"
 
+// endpoints identifies the calls made by the client and lists the endpoints
+// that anonymous clients can read.
+var endpoints = sourcehut.Endpoints{
+	Service: sourcehut.ServiceTodo,
+	Public: map[string]bool{
+		"version":                         true,
+		"user/{username}":                 true,
+		"user/{username}/trackers":        true,
+		"user/{username}/trackers/{name}": true,
+	},
+}
+
 // Option is used to configure an API client.
 type Option func(*Client) error
 
@@ -56,6 +69,17 @@ func Base(base string) Option {
 	}
 }
 
+// Instance returns an option that configures the client to use the todo API
+// of the provided Sourcehut instance and the sourcehut.Client that the instance
+// was created with.
+// Options that follow it, such as Base, override its settings.
+func Instance(inst *sourcehut.Instance) Option {
+	return func(c *Client) error {
+		c.srhtClient = inst.Client()
+		return Base(inst.BaseURL(endpoints.Service))(c)
+	}
+}
+
 // Client handles communication with the issue tracker related methods of the
 // Sourcehut API.
 //
@@ -90,25 +114,27 @@ func NewClient(opts ...Option) (*Client, error) {
 
 // GetUser returns information about the provided username, or the currently
 // authenticated user if the username is empty.
-func (c *Client) GetUser(username string) (sourcehut.User, error) {
+func (c *Client) GetUser(ctx context.Context, username string) (sourcehut.User, error) {
 	user := sourcehut.User{}
-	_, err := c.do("GET", path.Join("user", username), "", nil, &user)
+	_, err := c.do(endpoints.UserCall(ctx, username), "GET", path.Join("user", username), "", nil, &user)
 	return user, err
 }
 
 // Version returns the version of the API.
 //
 // API docs: https://man.sr.ht/api-conventions.md#get-apiversion
-func (c *Client) Version() (string, error) {
+func (c *Client) Version(ctx context.Context) (string, error) {
+	ctx = endpoints.Call(ctx, "version")
 	var ver struct {
 		Version string `json:"version"`
 	}
-	_, err := c.do("GET", "version", "", nil, &ver)
+	_, err := c.do(ctx, "GET", "version", "", nil, &ver)
 	return ver.Version, err
 }
 
 // NewTracker creates and returns a new repository from the provided template.
-func (c *Client) NewTracker(name, description string) (*Tracker, error) {
+func (c *Client) NewTracker(ctx context.Context, name, description string) (*Tracker, error) {
+	ctx = endpoints.Call(ctx, "trackers")
 	jsonTracker, err := json.Marshal(struct {
 		Name string `json:"name"`
 		Desc string `json:"description"`
@@ -121,7 +147,7 @@ func (c *Client) NewTracker(name, description string) (*Tracker, error) {
 	}
 
 	newTracker := &Tracker{}
-	_, err = c.do("POST", "trackers", "application/json", bytes.NewReader(jsonTracker), newTracker)
+	_, err = c.do(ctx, "POST", "trackers", "application/json", bytes.NewReader(jsonTracker), newTracker)
 	if err != nil {
 		return nil, err
 	}
@@ -131,15 +157,16 @@ func (c *Client) NewTracker(name, description string) (*Tracker, error) {
 // Tracker returns information about a specific issue tracker owned by the
 // provided username.
 // If an empty username is provided, the authenticated user is used.
-func (c *Client) Tracker(username, tracker string) (*Tracker, error) {
-	p := "trackers"
+func (c *Client) Tracker(ctx context.Context, username, tracker string) (*Tracker, error) {
+	p, endpoint := "trackers", "trackers/{name}"
 	if username != "" {
-		p = "user/" + url.PathEscape(username) + "/trackers"
+		p, endpoint = "user/"+url.PathEscape(username)+"/trackers", "user/{username}/trackers/{name}"
 	}
 	p = path.Join(p, url.PathEscape(tracker))
+	ctx = endpoints.Call(ctx, endpoint)
 
 	newTracker := &Tracker{}
-	_, err := c.do("GET", p, "", nil, newTracker)
+	_, err := c.do(ctx, "GET", p, "", nil, newTracker)
 	if err != nil {
 		return nil, err
 	}
@@ -150,17 +177,20 @@ func (c *Client) Tracker(username, tracker string) (*Tracker, error) {
 // Trackers returns an iterator over all issue trackers owned by the provided
 // username.
 // If an empty username is provided, the authenticated user is used.
-func (c *Client) Trackers(username string) (TrackerIter, error) {
-	path := "trackers"
+func (c *Client) Trackers(ctx context.Context, username string) (TrackerIter, error) {
+	path, endpoint := "trackers", "trackers"
 	if username != "" {
-		path = "user/" + url.PathEscape(username) + "/trackers"
+		path, endpoint = "user/"+url.PathEscape(username)+"/trackers", "user/{username}/trackers"
 	}
-	return c.trackers("GET", path, nil)
+	return c.trackers(endpoints.Call(ctx, endpoint), "GET", path, nil)
 }
 
-func (c *Client) do(method, u, contentType string, body io.Reader, v interface{}) (*http.Response, error) {
+func (c *Client) do(ctx context.Context, method, u, contentType string, body io.Reader, v interface{}) (*http.Response, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return nil, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return nil, err
 	}
@@ -170,14 +200,14 @@ func (c *Client) do(method, u, contentType string, body io.Reader, v interface{}
 	return c.srhtClient.Do(req, v)
 }
 
-func (c *Client) trackers(method, u string, body io.Reader) (TrackerIter, error) {
+func (c *Client) trackers(ctx context.Context, method, u string, body io.Reader) (TrackerIter, error) {
+	if err := endpoints.Authorize(ctx, c.srhtClient, method); err != nil {
+		return TrackerIter{}, err
+	}
 	u = c.baseURL.String() + u
-	req, err := http.NewRequest(method, u, body)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return TrackerIter{}, err
 	}
-	iter := c.srhtClient.List(req, func() interface{} {
-		return &Tracker{}
-	})
-	return TrackerIter{Iter: iter}, nil
+	return TrackerIter{Iter: sourcehut.List[*Tracker](c.srhtClient, req)}, nil
 }
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package todo_test
+
+import (
+	"context"
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/replaytest"
+	"git.sr.ht/~wombelix/sourcehut-go/todo"
+)
+
+func newClient(t *testing.T, cassette string) *todo.Client {
+	t.Helper()
+	client, err := todo.NewClient(todo.SrhtClient(replaytest.Client(t, cassette)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestTrackers(t *testing.T) {
+	const name = "sourcehut-go-test"
+	client := newClient(t, "trackers")
+	ctx := context.Background()
+
+	tracker, err := client.NewTracker(ctx, name, "Created by the sourcehut-go tests")
+	if err != nil {
+		t.Fatalf("Error creating tracker: %v", err)
+	}
+	if tracker.Name != name || tracker.Desc != "Created by the sourcehut-go tests" {
+		t.Errorf("Unexpected tracker: %+v", tracker)
+	}
+
+	tracker, err = client.Tracker(ctx, "", name)
+	if err != nil {
+		t.Fatalf("Error fetching tracker: %v", err)
+	}
+	if tracker.Owner.CanonicalName == "" || len(tracker.Perms.User) == 0 {
+		t.Errorf("Unexpected tracker: %+v", tracker)
+	}
+
+	trackers, err := client.Trackers(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for tr, err := range trackers.All() {
+		if err != nil {
+			t.Fatalf("Error listing trackers: %v", err)
+		}
+		found = found || tr.Name == name
+	}
+	if !found {
+		t.Errorf("Tracker %q was not listed", name)
+	}
+
+	_, err = client.Tracker(ctx, "~sourcehut-go-nobody", name)
+	if !sourcehut.IsNotFound(err) {
+		t.Errorf("Expected not found error for unknown user, got: %v", err)
+	}
+}
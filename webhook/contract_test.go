@@ -0,0 +1,20 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package webhook_test
+
+import (
+	"testing"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/contract"
+	"git.sr.ht/~wombelix/sourcehut-go/webhook"
+)
+
+func TestContract(t *testing.T) {
+	contract.Check(t, "testdata/contract.json", map[string]func() interface{}{
+		"Deleted": func() interface{} { return &webhook.Deleted{} },
+		"Push":    func() interface{} { return &webhook.Push{} },
+		"Ticket":  func() interface{} { return &webhook.Ticket{} },
+	})
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package webhook
+
+import (
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+	"git.sr.ht/~wombelix/sourcehut-go/lists"
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+	"git.sr.ht/~wombelix/sourcehut-go/todo"
+)
+
+// Event is a webhook event whose payload is decoded into a T.
+type Event[T any] struct {
+	name string
+}
+
+// Name returns the name of the event as it appears in the X-Webhook-Event
+// header (eg. "repo:post-update").
+func (e Event[T]) Name() string {
+	return e.name
+}
+
+// Events delivered by the meta service.
+var (
+	MetaProfileUpdate = Event[meta.User]{"profile:update"}
+	MetaPGPKeyAdd     = Event[meta.PGPKey]{"pgp-key:add"}
+	MetaPGPKeyRemove  = Event[meta.KeyRemoved]{"pgp-key:remove"}
+	MetaSSHKeyAdd     = Event[meta.SSHKey]{"ssh-key:add"}
+	MetaSSHKeyRemove  = Event[meta.KeyRemoved]{"ssh-key:remove"}
+)
+
+// Events delivered by the git service.
+var (
+	GitRepoCreate = Event[git.Repo]{"repo:create"}
+	GitRepoUpdate = Event[git.Repo]{"repo:update"}
+	GitRepoDelete = Event[Deleted]{"repo:delete"}
+	GitPostUpdate = Event[Push]{"repo:post-update"}
+)
+
+// Events delivered by the todo service.
+var (
+	TodoTrackerCreate = Event[todo.Tracker]{"tracker:create"}
+	TodoTrackerUpdate = Event[todo.Tracker]{"tracker:update"}
+	TodoTrackerDelete = Event[Deleted]{"tracker:delete"}
+	TodoTicketCreate  = Event[Ticket]{"ticket:create"}
+	TodoTicketUpdate  = Event[Ticket]{"ticket:update"}
+)
+
+// Events delivered by the lists service.
+var (
+	ListsListCreate   = Event[lists.List]{"list:create"}
+	ListsListUpdate   = Event[lists.List]{"list:update"}
+	ListsListDelete   = Event[Deleted]{"list:delete"}
+	ListsPostReceived = Event[lists.Post]{"post:received"}
+)
+
+// Deleted is the payload of events for resources that have been deleted.
+type Deleted struct {
+	ID int64 `json:"id"`
+}
+
+// Push is the payload of the repo:post-update event, which is delivered after
+// refs in a repository have been updated.
+type Push struct {
+	// ID uniquely identifies the push.
+	ID     string              `json:"push"`
+	Pusher sourcehut.ShortUser `json:"pusher"`
+	Refs   []RefUpdate         `json:"refs"`
+}
+
+// RefUpdate describes the change to a single ref in a push.
+type RefUpdate struct {
+	Name string `json:"name"`
+
+	// Old is nil if the ref was created.
+	Old *git.Commit `json:"old"`
+
+	// New is nil if the ref was deleted.
+	New *git.Commit `json:"new"`
+
+	AnnotatedTag *struct {
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	} `json:"annotated_tag"`
+}
+
+// Ticket is the payload of ticket events.
+type Ticket struct {
+	ID          int64                 `json:"id"`
+	Ref         string                `json:"ref"`
+	Tracker     todo.ShortTracker     `json:"tracker"`
+	Subject     string                `json:"subject"`
+	Description string                `json:"description"`
+	Created     time.Time             `json:"created"`
+	Updated     time.Time             `json:"updated"`
+	Submitter   sourcehut.ShortUser   `json:"submitter"`
+	Status      string                `json:"status"`
+	Resolution  string                `json:"resolution"`
+	Labels      []string              `json:"labels"`
+	Assignees   []sourcehut.ShortUser `json:"assignees"`
+}
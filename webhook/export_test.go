@@ -0,0 +1,14 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package webhook
+
+// RememberedNonces is exposed in the test package only; it returns the number
+// of nonces held in memory by h so that the expiry of nonces can be tested.
+func RememberedNonces(h *Handler) int {
+	s := h.nonces.(*memoryNonces)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
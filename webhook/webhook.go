@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package webhook receives webhook deliveries from Sourcehut services.
+//
+// A Handler verifies the signature of every delivery, rejects deliveries that
+// are replayed within a window, and passes the decoded payload to the callback
+// registered for its event:
+//
+//	h := webhook.NewHandler(key)
+//	webhook.Handle(h, webhook.GitPostUpdate, func(ctx context.Context, d webhook.Delivery, push webhook.Push) error {
+//		for _, ref := range push.Refs {
+//			log.Printf("%s updated %s", push.Pusher.CanonicalName, ref.Name)
+//		}
+//		return nil
+//	})
+//	http.Handle("/webhook", h)
+//
+// Subscriptions are created using the service clients, such as
+// meta.Client.NewWebhook.
+//
+// API docs: https://man.sr.ht/api-conventions.md#webhooks
+package webhook
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/webhooksig"
+)
+
+// Headers set on webhook deliveries.
+const (
+	HeaderEvent     = webhooksig.HeaderEvent
+	HeaderDelivery  = webhooksig.HeaderDelivery
+	HeaderSignature = webhooksig.HeaderSignature
+	HeaderNonce     = webhooksig.HeaderNonce
+)
+
+// DefaultReplayWindow is the replay window used by handlers unless the
+// ReplayWindow option is provided.
+const DefaultReplayWindow = time.Hour
+
+// maxPayload is the largest payload that a Handler will read.
+const maxPayload = 1 << 20
+
+// Errors returned by Verify.
+var (
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrReplayed         = errors.New("webhook: delivery has already been received")
+)
+
+// Delivery describes a single webhook delivery.
+type Delivery struct {
+	// ID uniquely identifies the delivery.
+	ID string
+
+	// Event is the name of the event (eg. "ticket:create").
+	Event string
+
+	// Payload is the raw (verified) body of the delivery.
+	Payload []byte
+}
+
+// Option is used to configure a Handler.
+type Option func(*Handler)
+
+// ReplayWindow returns an option that configures how long the nonces of
+// deliveries are remembered.
+// A delivery that reuses a nonce seen within the window is rejected.
+// If unspecified, DefaultReplayWindow is used.
+func ReplayWindow(d time.Duration) Option {
+	return func(h *Handler) {
+		h.window = d
+	}
+}
+
+// Nonces returns an option that configures where the nonces of deliveries are
+// remembered.
+// If unspecified, they are kept in memory by the Handler.
+func Nonces(store NonceStore) Option {
+	return func(h *Handler) {
+		h.nonces = store
+	}
+}
+
+// NonceStore remembers the nonces of deliveries so that replayed deliveries
+// can be rejected.
+// Implementations must be safe for concurrent use.
+//
+// A store that persists nonces, such as a database table or a key-value store
+// with expiring keys, keeps rejecting replays across restarts of the program
+// and can be shared by several instances of a Handler that receive the same
+// deliveries.
+type NonceStore interface {
+	// Add records nonce until the provided time and reports whether it was
+	// already recorded and had not yet expired.
+	Add(nonce string, until time.Time) (seen bool, err error)
+}
+
+// Handler is an http.Handler that receives webhook deliveries.
+//
+// Deliveries with an invalid signature or a nonce that has already been seen
+// are rejected with 401 Unauthorized, and payloads that cannot be decoded with
+// 400 Bad Request.
+// If the callback returns an error, or the nonce cannot be stored, the response
+// is 500 Internal Server Error, otherwise it is 204 No Content, including for
+// events without a callback.
+// Callbacks are passed the context of the request.
+//
+// Deliveries do not include the time at which they were sent, so replay
+// protection only holds for as long as nonces are remembered: a delivery that
+// is captured and sent again after the replay window has passed, or after the
+// program restarts while nonces are kept in memory, is accepted.
+// Use the Nonces option with a persistent NonceStore and a long ReplayWindow to
+// reject replays for longer.
+//
+// A Handler is safe for concurrent use, and callbacks may be registered while
+// it is serving requests.
+type Handler struct {
+	key    ed25519.PublicKey
+	window time.Duration
+	nonces NonceStore
+
+	mu     sync.Mutex
+	routes map[string]func(context.Context, Delivery) error
+}
+
+// NewHandler returns a handler that verifies deliveries using key, the webhook
+// public key published by the Sourcehut instance.
+func NewHandler(key ed25519.PublicKey, opts ...Option) *Handler {
+	h := &Handler{
+		key:    key,
+		window: DefaultReplayWindow,
+		routes: make(map[string]func(context.Context, Delivery) error),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.nonces == nil {
+		h.nonces = newMemoryNonces()
+	}
+	return h
+}
+
+// Handle registers f as the callback for the event e, replacing any existing
+// callback.
+func Handle[T any](h *Handler, e Event[T], f func(context.Context, Delivery, T) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.routes[e.name] = func(ctx context.Context, d Delivery) error {
+		var v T
+		if err := json.Unmarshal(d.Payload, &v); err != nil {
+			return decodeError{err: err}
+		}
+		return f(ctx, d, v)
+	}
+}
+
+// Verify checks the signature of a delivery and that its nonce has not been
+// seen within the replay window.
+// The nonce is remembered if the delivery is valid.
+//
+// Errors from the NonceStore are returned as is.
+func (h *Handler) Verify(payload []byte, signature, nonce string) error {
+	if !webhooksig.Verify(h.key, payload, signature, nonce) {
+		return ErrInvalidSignature
+	}
+	seen, err := h.nonces.Add(nonce, time.Now().Add(h.window))
+	switch {
+	case err != nil:
+		return err
+	case seen:
+		return ErrReplayed
+	}
+	return nil
+}
+
+// ServeHTTP satisfies the http.Handler interface for Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxPayload))
+	if err != nil {
+		http.Error(w, "error reading payload", http.StatusBadRequest)
+		return
+	}
+	err = h.Verify(payload, req.Header.Get(HeaderSignature), req.Header.Get(HeaderNonce))
+	switch {
+	case errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrReplayed):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, "error storing nonce", http.StatusInternalServerError)
+		return
+	}
+
+	d := Delivery{
+		ID:      req.Header.Get(HeaderDelivery),
+		Event:   req.Header.Get(HeaderEvent),
+		Payload: payload,
+	}
+	h.mu.Lock()
+	f := h.routes[d.Event]
+	h.mu.Unlock()
+	if f != nil {
+		err = f(req.Context(), d)
+	}
+	var decodeErr decodeError
+	switch {
+	case errors.As(err, &decodeErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, "error handling event", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// decodeError is returned by callbacks if the payload could not be decoded.
+type decodeError struct {
+	err error
+}
+
+func (err decodeError) Error() string {
+	return fmt.Sprintf("error decoding payload: %v", err.err)
+}
+
+// memoryNonces is the NonceStore used by default.
+// Nonces are queued in the order they were added, which is also the order in
+// which they expire since a Handler always adds them with the same window, so
+// expired nonces are removed from the front of the queue as new ones are added
+// instead of searching for them.
+type memoryNonces struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+	queue []queuedNonce
+}
+
+type queuedNonce struct {
+	nonce string
+	until time.Time
+}
+
+func newMemoryNonces() *memoryNonces {
+	return &memoryNonces{until: make(map[string]time.Time)}
+}
+
+// Add satisfies the NonceStore interface.
+func (s *memoryNonces) Add(nonce string, until time.Time) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.queue) > 0 && !s.queue[0].until.After(now) {
+		q := s.queue[0]
+		// The nonce may have been added again after it expired, in which case
+		// it is still queued further back.
+		if s.until[q.nonce].Equal(q.until) {
+			delete(s.until, q.nonce)
+		}
+		s.queue = s.queue[1:]
+	}
+	if t, ok := s.until[nonce]; ok && t.After(now) {
+		return true, nil
+	}
+	s.until[nonce] = until
+	s.queue = append(s.queue, queuedNonce{nonce: nonce, until: until})
+	return false, nil
+}
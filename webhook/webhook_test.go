@@ -0,0 +1,262 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.sr.ht/~wombelix/sourcehut-go"
+	"git.sr.ht/~wombelix/sourcehut-go/git"
+	"git.sr.ht/~wombelix/sourcehut-go/internal/testlog"
+	"git.sr.ht/~wombelix/sourcehut-go/lists"
+	"git.sr.ht/~wombelix/sourcehut-go/meta"
+	"git.sr.ht/~wombelix/sourcehut-go/webhook"
+	"git.sr.ht/~wombelix/sourcehut-go/webhook/webhooktest"
+)
+
+func newServer(t *testing.T, h *webhook.Handler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(h)
+	server.Config.ErrorLog = testlog.New(t)
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func deliver(t *testing.T, server *httptest.Server, req *http.Request) int {
+	t.Helper()
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Error delivering webhook: %v", err)
+	}
+	/* #nosec */
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+var handlerTests = [...]struct {
+	event   string
+	payload interface{}
+	code    int
+	called  string
+}{
+	0: {
+		event: webhook.GitPostUpdate.Name(),
+		payload: webhook.Push{
+			ID:     "push",
+			Pusher: sourcehut.ShortUser{Name: "jdoe"},
+			Refs:   []webhook.RefUpdate{{Name: "refs/heads/main", New: &git.Commit{ID: "abc"}}},
+		},
+		code:   http.StatusNoContent,
+		called: "repo:post-update",
+	},
+	1: {
+		event:   webhook.TodoTicketCreate.Name(),
+		payload: webhook.Ticket{ID: 1, Subject: "Crash"},
+		code:    http.StatusNoContent,
+		called:  "ticket:create",
+	},
+	2: {
+		event:   webhook.ListsPostReceived.Name(),
+		payload: lists.Post{Patch: true},
+		code:    http.StatusNoContent,
+		called:  "post:received",
+	},
+	3: {
+		event:   webhook.MetaSSHKeyRemove.Name(),
+		payload: meta.KeyRemoved{ID: 1},
+		code:    http.StatusInternalServerError,
+		called:  "ssh-key:remove",
+	},
+	4: {event: webhook.GitRepoCreate.Name(), payload: git.Repo{Name: "x"}, code: http.StatusNoContent},
+	5: {event: "unknown:event", payload: []byte(`{}`), code: http.StatusNoContent},
+	6: {event: webhook.TodoTicketCreate.Name(), payload: []byte(`{"id": "one"}`), code: http.StatusBadRequest},
+}
+
+func TestHandler(t *testing.T) {
+	signer := webhooktest.NewSigner()
+	h := webhook.NewHandler(signer.PublicKey())
+	server := newServer(t, h)
+
+	var called string
+	webhook.Handle(h, webhook.GitPostUpdate, func(_ context.Context, d webhook.Delivery, push webhook.Push) error {
+		called = d.Event
+		if push.ID != "push" || len(push.Refs) != 1 || push.Refs[0].New.ID != "abc" || push.Refs[0].Old != nil {
+			t.Errorf("Unexpected push: %+v", push)
+		}
+		return nil
+	})
+	webhook.Handle(h, webhook.TodoTicketCreate, func(_ context.Context, d webhook.Delivery, ticket webhook.Ticket) error {
+		called = d.Event
+		if ticket.ID != 1 || ticket.Subject != "Crash" {
+			t.Errorf("Unexpected ticket: %+v", ticket)
+		}
+		return nil
+	})
+	webhook.Handle(h, webhook.ListsPostReceived, func(_ context.Context, d webhook.Delivery, post lists.Post) error {
+		called = d.Event
+		if !post.Patch {
+			t.Errorf("Unexpected post: %+v", post)
+		}
+		return nil
+	})
+	webhook.Handle(h, webhook.MetaSSHKeyRemove, func(_ context.Context, d webhook.Delivery, _ meta.KeyRemoved) error {
+		called = d.Event
+		return errors.New("callback failed")
+	})
+
+	for i, tc := range handlerTests {
+		called = ""
+		req, err := signer.NewRequest(server.URL, tc.event, tc.payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code := deliver(t, server, req); code != tc.code {
+			t.Errorf("%d: wrong status: want=%d, got=%d", i, tc.code, code)
+		}
+		if called != tc.called {
+			t.Errorf("%d: wrong callback: want=%q, got=%q", i, tc.called, called)
+		}
+	}
+}
+
+func TestHandlerRejects(t *testing.T) {
+	signer := webhooktest.NewSigner()
+	server := newServer(t, webhook.NewHandler(signer.PublicKey()))
+
+	// A delivery signed with another key.
+	req, err := webhooktest.NewSigner().NewRequest(server.URL, "repo:create", git.Repo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := deliver(t, server, req); code != http.StatusUnauthorized {
+		t.Errorf("Expected unauthorized for wrong key, got %d", code)
+	}
+
+	// A delivery whose nonce was changed after signing.
+	req, err = signer.NewRequest(server.URL, "repo:create", git.Repo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(webhook.HeaderNonce, "tampered")
+	if code := deliver(t, server, req); code != http.StatusUnauthorized {
+		t.Errorf("Expected unauthorized for tampered nonce, got %d", code)
+	}
+
+	req, err = http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := deliver(t, server, req); code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected method not allowed, got %d", code)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	signer := webhooktest.NewSigner()
+	payload := []byte(`{"id": 1}`)
+	h := http.Header{}
+	signer.Sign(h, "repo:delete", payload)
+	sig, nonce := h.Get(webhook.HeaderSignature), h.Get(webhook.HeaderNonce)
+
+	handler := webhook.NewHandler(signer.PublicKey())
+	if err := handler.Verify(payload, sig, nonce); err != nil {
+		t.Fatalf("Unexpected error verifying delivery: %v", err)
+	}
+	if err := handler.Verify(payload, sig, nonce); !errors.Is(err, webhook.ErrReplayed) {
+		t.Errorf("Expected replayed delivery to be rejected, got: %v", err)
+	}
+	if n := webhook.RememberedNonces(handler); n != 1 {
+		t.Errorf("Expected 1 remembered nonce, got %d", n)
+	}
+}
+
+func TestNonceExpiry(t *testing.T) {
+	signer := webhooktest.NewSigner()
+	payload := []byte(`{"id": 1}`)
+	handler := webhook.NewHandler(signer.PublicKey(), webhook.ReplayWindow(200*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		h := http.Header{}
+		signer.Sign(h, "repo:delete", payload)
+		if err := handler.Verify(payload, h.Get(webhook.HeaderSignature), h.Get(webhook.HeaderNonce)); err != nil {
+			t.Fatalf("Unexpected error verifying delivery: %v", err)
+		}
+	}
+	if n := webhook.RememberedNonces(handler); n != 3 {
+		t.Errorf("Expected 3 remembered nonces, got %d", n)
+	}
+
+	// Nonces that have expired are forgotten when the next delivery arrives.
+	time.Sleep(250 * time.Millisecond)
+	h := http.Header{}
+	signer.Sign(h, "repo:delete", payload)
+	if err := handler.Verify(payload, h.Get(webhook.HeaderSignature), h.Get(webhook.HeaderNonce)); err != nil {
+		t.Fatalf("Unexpected error verifying delivery: %v", err)
+	}
+	if n := webhook.RememberedNonces(handler); n != 1 {
+		t.Errorf("Expected expired nonces to be forgotten, got %d remembered nonces", n)
+	}
+}
+
+// nonceStore is a NonceStore that records its calls.
+type nonceStore struct {
+	err   error
+	until map[string]time.Time
+}
+
+func (s *nonceStore) Add(nonce string, until time.Time) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	_, seen := s.until[nonce]
+	s.until[nonce] = until
+	return seen, nil
+}
+
+func TestNonceStore(t *testing.T) {
+	signer := webhooktest.NewSigner()
+	store := &nonceStore{until: make(map[string]time.Time)}
+	h := webhook.NewHandler(signer.PublicKey(), webhook.Nonces(store), webhook.ReplayWindow(24*time.Hour))
+	server := newServer(t, h)
+
+	payload := []byte(`{"id": 1}`)
+	header := http.Header{}
+	signer.Sign(header, "repo:delete", payload)
+	nonce := header.Get(webhook.HeaderNonce)
+	newRequest := func() *http.Request {
+		req, err := http.NewRequest("POST", server.URL, bytes.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header = header.Clone()
+		return req
+	}
+
+	start := time.Now()
+	if code := deliver(t, server, newRequest()); code != http.StatusNoContent {
+		t.Errorf("Unexpected status for first delivery: %d", code)
+	}
+	until, ok := store.until[nonce]
+	if !ok {
+		t.Fatalf("Nonce was not added to the store")
+	}
+	if d := until.Sub(start); d < 24*time.Hour || d > 25*time.Hour {
+		t.Errorf("Nonce stored until %v, expected the end of the replay window", until)
+	}
+	if code := deliver(t, server, newRequest()); code != http.StatusUnauthorized {
+		t.Errorf("Expected unauthorized for replayed delivery, got %d", code)
+	}
+
+	store.err = errors.New("store unavailable")
+	if code := deliver(t, server, newRequest()); code != http.StatusInternalServerError {
+		t.Errorf("Expected internal server error if the nonce cannot be stored, got %d", code)
+	}
+}
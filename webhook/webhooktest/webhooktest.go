@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2026 The SourceHut API Contributors
+//
+// SPDX-License-Identifier: BSD-2-Clause
+
+// Package webhooktest signs synthetic webhook deliveries for use in tests.
+//
+// A Signer has its own key pair, so handlers under test are created with the
+// signer's public key instead of the key of a real Sourcehut instance:
+//
+//	signer := webhooktest.NewSigner()
+//	h := webhook.NewHandler(signer.PublicKey())
+//	srv := httptest.NewServer(h)
+//	defer srv.Close()
+//
+//	req, err := signer.NewRequest(srv.URL, webhook.GitRepoCreate.Name(), git.Repo{Name: "example"})
+package webhooktest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+
+	"git.sr.ht/~wombelix/sourcehut-go/internal/webhooksig"
+)
+
+// Signer signs webhook deliveries.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner returns a signer with a newly generated key.
+func NewSigner() *Signer {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		// GenerateKey only fails if the system's random number generator does.
+		panic(err)
+	}
+	return &Signer{key: key}
+}
+
+// PublicKey returns the key that deliveries signed by s are verified with.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Sign signs payload using a new random nonce and sets the signature, nonce,
+// and event headers on h.
+// A random delivery ID is also set unless h already has one.
+func (s *Signer) Sign(h http.Header, event string, payload []byte) {
+	nonce := webhooksig.Nonce()
+	h.Set(webhooksig.HeaderEvent, event)
+	h.Set(webhooksig.HeaderNonce, nonce)
+	h.Set(webhooksig.HeaderSignature, webhooksig.Sign(s.key, payload, nonce))
+	if h.Get(webhooksig.HeaderDelivery) == "" {
+		h.Set(webhooksig.HeaderDelivery, webhooksig.Nonce())
+	}
+}
+
+// NewRequest returns a signed delivery of the event with v encoded as JSON as
+// its payload, or the payload itself if v is a []byte.
+func (s *Signer) NewRequest(url, event string, v interface{}) (*http.Request, error) {
+	payload, ok := v.([]byte)
+	if !ok {
+		var err error
+		payload, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.Sign(req.Header, event, payload)
+	return req, nil
+}